@@ -0,0 +1,100 @@
+// Package article turns the text-view HTML returned by BookmarkService.GetText into a structured Article - title,
+// byline, paragraphs, images and links - and renders it back as plain text or CommonMark.
+package article
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// Kind is the kind of a paragraph
+type Kind int
+
+const (
+	// Text is a regular paragraph
+	Text Kind = iota
+	// Heading is a section title, Paragraph.Level is its level from 1 to 6
+	Heading
+	// ListItem is an item of a list, Paragraph.Level is the nesting depth starting from 0
+	ListItem
+	// Quote is a paragraph of a block quote
+	Quote
+	// Code is a preformatted block, its single span holds the text as is
+	Code
+	// Rule is a thematic break, it has no spans
+	Rule
+)
+
+// Article is the readable content of a bookmark
+type Article struct {
+	Title      string
+	Byline     string
+	Paragraphs []Paragraph
+	// Images and Links list every image and link of the paragraphs, in order
+	Images []Image
+	Links  []Link
+}
+
+// Paragraph is a block of the article
+type Paragraph struct {
+	Kind  Kind
+	Level int
+	// Number is the number of an ordered list item, 0 for an unordered one
+	Number int
+	Spans  []Span
+}
+
+// Span is a run of text sharing the same formatting. An image span has an Image and no text
+type Span struct {
+	Text     string
+	Emphasis bool
+	Strong   bool
+	Code     bool
+	// Link is the target of the link the span is part of, if any
+	Link  string
+	Image *Image
+}
+
+// Image is an image of the article
+type Image struct {
+	Src   string
+	Alt   string
+	Title string
+}
+
+// Link is a link of the article
+type Link struct {
+	Href string
+	Text string
+}
+
+// Text returns the text of the paragraph without formatting, images are left out
+func (p Paragraph) Text() string {
+	var b strings.Builder
+	for _, s := range p.Spans {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
+
+// Parse reads the text-view HTML of a bookmark. It copes with fragments and arbitrary HTML as well
+func Parse(r io.Reader) (*Article, error) {
+	return parse(r)
+}
+
+// FromString is like Parse, for the string returned by BookmarkService.GetText
+func FromString(s string) (*Article, error) {
+	return parse(strings.NewReader(s))
+}
+
+// Get fetches the text view of the bookmark and parses it
+func Get(ctx context.Context, client *instapaper.Client, bookmarkID int) (*Article, error) {
+	text, err := client.Bookmarks.GetTextContext(ctx, bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+	return FromString(text)
+}
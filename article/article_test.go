@@ -0,0 +1,149 @@
+package article
+
+import (
+	"reflect"
+	"testing"
+)
+
+// textView is shaped like the HTML returned by /bookmarks/get_text
+const textView = `<!DOCTYPE html>
+<html>
+<head>
+	<title>On Call Shouldn't Suck</title>
+	<style>body { color: black }</style>
+</head>
+<body>
+<div id="titlebar">
+	<h1>On Call Shouldn't Suck</h1>
+	<div class="metadata"><a class="original" href="https://example.com/a">example.com</a></div>
+</div>
+<div id="story">
+	<h1>On Call Shouldn't Suck</h1>
+	<p class="byline">By Jane Doe</p>
+	<p>Being <em>on call</em> is
+		<strong>hard</strong>. Read <a href="https://example.com/guide">the guide</a>.</p>
+	<script>track()</script>
+	<figure><img src="https://example.com/pager.png" alt="A pager"><figcaption>The pager</figcaption></figure>
+	<h2>Rules</h2>
+	<ol>
+		<li>Page *rarely*</li>
+		<li><p>Fix the <code>alerts</code></p>
+			<ul><li>first line<br>second line</li></ul>
+		</li>
+	</ol>
+	<blockquote><p>Sleep matters.</p><p>So does #health.</p></blockquote>
+	<pre><code>
+if paged {
+	sleep()
+}
+</code></pre>
+	<hr>
+	<p>1. Not a list</p>
+</div>
+</body>
+</html>`
+
+func TestParse(t *testing.T) {
+	a, err := FromString(textView)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if a.Title != "On Call Shouldn't Suck" || a.Byline != "Jane Doe" {
+		t.Errorf("unexpected title %q and byline %q", a.Title, a.Byline)
+	}
+	pager := &Image{Src: "https://example.com/pager.png", Alt: "A pager"}
+	expected := []Paragraph{
+		{Kind: Text, Spans: []Span{
+			{Text: "Being "}, {Text: "on call", Emphasis: true}, {Text: " is "}, {Text: "hard", Strong: true},
+			{Text: ". Read "}, {Text: "the guide", Link: "https://example.com/guide"}, {Text: "."},
+		}},
+		{Kind: Text, Spans: []Span{{Image: pager}}},
+		{Kind: Text, Spans: []Span{{Text: "The pager"}}},
+		{Kind: Heading, Level: 2, Spans: []Span{{Text: "Rules"}}},
+		{Kind: ListItem, Number: 1, Spans: []Span{{Text: "Page *rarely*"}}},
+		{Kind: ListItem, Number: 2, Spans: []Span{{Text: "Fix the "}, {Text: "alerts", Code: true}}},
+		{Kind: ListItem, Level: 1, Spans: []Span{{Text: "first line"}, {Text: "\n"}, {Text: "second line"}}},
+		{Kind: Quote, Spans: []Span{{Text: "Sleep matters."}}},
+		{Kind: Quote, Spans: []Span{{Text: "So does #health."}}},
+		{Kind: Code, Spans: []Span{{Text: "if paged {\n\tsleep()\n}"}}},
+		{Kind: Rule},
+		{Kind: Text, Spans: []Span{{Text: "1. Not a list"}}},
+	}
+	if !reflect.DeepEqual(a.Paragraphs, expected) {
+		t.Errorf("expected\n%+v\ngot\n%+v", expected, a.Paragraphs)
+	}
+	if !reflect.DeepEqual(a.Images, []Image{*pager}) {
+		t.Errorf("unexpected images %+v", a.Images)
+	}
+	if expected := []Link{{Href: "https://example.com/guide", Text: "the guide"}}; !reflect.DeepEqual(a.Links, expected) {
+		t.Errorf("expected links %+v, got %+v", expected, a.Links)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	a, _ := FromString(textView)
+	expected := `On Call Shouldn't Suck
+
+Jane Doe
+
+Being on call is hard. Read the guide.
+
+The pager
+
+Rules
+
+1. Page *rarely*
+2. Fix the alerts
+  - first line
+    second line
+
+Sleep matters.
+
+So does #health.
+
+if paged {
+	sleep()
+}
+
+1. Not a list
+`
+	if text := a.PlainText(); text != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, text)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	a, _ := FromString(textView)
+	expected := "# On Call Shouldn't Suck\n\n" +
+		"*Jane Doe*\n\n" +
+		"Being *on call* is **hard**. Read [the guide](https://example.com/guide).\n\n" +
+		"![A pager](https://example.com/pager.png)\n\n" +
+		"The pager\n\n" +
+		"## Rules\n\n" +
+		"1. Page \\*rarely\\*\n" +
+		"2. Fix the `alerts`\n" +
+		"    - first line\\\n" +
+		"      second line\n\n" +
+		"> Sleep matters.\n" +
+		">\n" +
+		"> So does #health.\n\n" +
+		"```\nif paged {\n\tsleep()\n}\n```\n\n" +
+		"---\n\n" +
+		"1\\. Not a list\n"
+	if md := a.Markdown(); md != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, md)
+	}
+}
+
+func TestParseFragment(t *testing.T) {
+	a, err := FromString(`<h1>Only a <i>fragment</i></h1><p>with <a href="/x"><img src="a.png"></a> <code>a` + "`" + `b</code></p>`)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if a.Title != "Only a fragment" || len(a.Paragraphs) != 1 {
+		t.Fatalf("expected the heading to become the title, got %+v", a)
+	}
+	if md := a.Markdown(); md != "# Only a fragment\n\nwith [![](a.png)](/x) ``a`b``\n" {
+		t.Errorf("unexpected markdown %q", md)
+	}
+}
@@ -0,0 +1,439 @@
+package article
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skipped elements never hold article content
+var skipped = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Svg:      true,
+	atom.Math:     true,
+	atom.Nav:      true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Input:    true,
+	atom.Select:   true,
+	atom.Textarea: true,
+}
+
+// blocks end the current paragraph when they start and when they end
+var blocks = map[atom.Atom]bool{
+	atom.Address:    true,
+	atom.Article:    true,
+	atom.Aside:      true,
+	atom.Blockquote: true,
+	atom.Body:       true,
+	atom.Dd:         true,
+	atom.Details:    true,
+	atom.Div:        true,
+	atom.Dl:         true,
+	atom.Dt:         true,
+	atom.Figcaption: true,
+	atom.Figure:     true,
+	atom.Footer:     true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Header:     true,
+	atom.Li:         true,
+	atom.Main:       true,
+	atom.Ol:         true,
+	atom.P:          true,
+	atom.Pre:        true,
+	atom.Section:    true,
+	atom.Summary:    true,
+	atom.Table:      true,
+	atom.Tr:         true,
+	atom.Ul:         true,
+}
+
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1,
+	atom.H2: 2,
+	atom.H3: 3,
+	atom.H4: 4,
+	atom.H5: 5,
+	atom.H6: 6,
+}
+
+// list is an open ul or ol element
+type list struct {
+	ordered bool
+	next    int
+}
+
+// state is what the enclosing elements tell about the text being read, it's restored when an element ends
+type state struct {
+	heading int
+	quote   int
+	pre     bool
+	lists   []list
+	em      int
+	strong  int
+	code    int
+	link    string
+}
+
+type parser struct {
+	article *Article
+	state
+	cur *Paragraph
+	// itemPending is set by a li element until its first paragraph starts
+	itemPending bool
+	linkText    *strings.Builder
+	// byline is the element the byline was taken from, it's not repeated in the paragraphs
+	byline *html.Node
+}
+
+func parse(r io.Reader) (*Article, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	a := &Article{}
+	if title := find(doc, func(n *html.Node) bool { return n.DataAtom == atom.Title }); title != nil {
+		a.Title = strings.TrimSpace(collapse(textOf(title)))
+	}
+	p := &parser{article: a}
+	a.Byline, p.byline = findByline(doc)
+
+	root := find(doc, func(n *html.Node) bool { return attr(n, "id") == "story" })
+	if root == nil {
+		root = find(doc, func(n *html.Node) bool { return n.DataAtom == atom.Article })
+	}
+	if root == nil {
+		root = doc
+	}
+	p.walk(root)
+	p.flush()
+
+	if a.Title == "" {
+		if h1 := find(doc, func(n *html.Node) bool { return n.DataAtom == atom.H1 }); h1 != nil {
+			a.Title = strings.TrimSpace(collapse(textOf(h1)))
+		}
+	}
+	// the text view repeats the title as the first heading
+	if len(a.Paragraphs) > 0 && a.Paragraphs[0].Kind == Heading && strings.TrimSpace(a.Paragraphs[0].Text()) == a.Title {
+		a.Paragraphs = a.Paragraphs[1:]
+	}
+	return a, nil
+}
+
+func (p *parser) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		p.text(n.Data)
+		return
+	case html.DocumentNode:
+		p.children(n)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if skipped[n.DataAtom] || n == p.byline {
+		return
+	}
+	switch n.DataAtom {
+	case atom.Br:
+		p.lineBreak()
+		return
+	case atom.Hr:
+		p.flush()
+		p.article.Paragraphs = append(p.article.Paragraphs, Paragraph{Kind: Rule})
+		return
+	case atom.Img:
+		p.image(n)
+		return
+	case atom.Td, atom.Th:
+		p.text(" ")
+	}
+
+	block := blocks[n.DataAtom]
+	if block {
+		p.flush()
+	}
+	saved := p.state
+	var linkText *strings.Builder
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		p.heading = headingLevels[n.DataAtom]
+	case atom.Blockquote:
+		p.quote++
+	case atom.Pre:
+		p.pre = true
+	case atom.Ul, atom.Ol:
+		l := list{ordered: n.DataAtom == atom.Ol, next: 1}
+		if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+			l.next = start
+		}
+		// a copy, so the list doesn't outlive the element when the state is restored
+		p.lists = append(append([]list(nil), p.lists...), l)
+	case atom.Li:
+		p.itemPending = true
+	case atom.Em, atom.I, atom.Cite, atom.Dfn:
+		p.em++
+	case atom.Strong, atom.B:
+		p.strong++
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		p.code++
+	case atom.A:
+		if href := strings.TrimSpace(attr(n, "href")); href != "" && p.linkText == nil {
+			p.link = href
+			linkText = &strings.Builder{}
+			p.linkText = linkText
+		}
+	}
+	p.children(n)
+	if linkText != nil {
+		p.article.Links = append(p.article.Links, Link{Href: p.link, Text: strings.TrimSpace(linkText.String())})
+		p.linkText = nil
+	}
+	if n.DataAtom == atom.Li {
+		p.itemPending = false
+	}
+	p.state = saved
+	if block {
+		p.flush()
+	}
+}
+
+func (p *parser) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.walk(c)
+	}
+}
+
+// start opens a paragraph, its kind depends on the enclosing elements
+func (p *parser) start() *Paragraph {
+	if p.cur != nil {
+		return p.cur
+	}
+	para := Paragraph{}
+	switch {
+	case p.pre:
+		para.Kind = Code
+	case p.heading > 0:
+		para.Kind = Heading
+		para.Level = p.heading
+	case p.itemPending && len(p.lists) > 0:
+		para.Kind = ListItem
+		para.Level = len(p.lists) - 1
+		if l := &p.lists[len(p.lists)-1]; l.ordered {
+			para.Number = l.next
+			l.next++
+		}
+		p.itemPending = false
+	case p.quote > 0:
+		para.Kind = Quote
+	}
+	p.cur = &para
+	return p.cur
+}
+
+// atLineStart tells whether leading white space would be dropped
+func (p *parser) atLineStart() bool {
+	if p.cur == nil || len(p.cur.Spans) == 0 {
+		return true
+	}
+	last := p.cur.Spans[len(p.cur.Spans)-1]
+	return last.Image == nil && (strings.HasSuffix(last.Text, " ") || strings.HasSuffix(last.Text, "\n"))
+}
+
+func (p *parser) text(s string) {
+	if p.pre {
+		p.add(Span{Text: s})
+		return
+	}
+	s = collapse(s)
+	if p.atLineStart() {
+		s = strings.TrimLeft(s, " ")
+	}
+	if s == "" {
+		return
+	}
+	if p.linkText != nil {
+		p.linkText.WriteString(s)
+	}
+	p.add(Span{
+		Text:     s,
+		Emphasis: p.em > 0,
+		Strong:   p.strong > 0,
+		Code:     p.code > 0,
+		Link:     p.link,
+	})
+}
+
+func (p *parser) lineBreak() {
+	if p.pre {
+		p.add(Span{Text: "\n"})
+		return
+	}
+	if p.cur == nil || len(p.cur.Spans) == 0 {
+		return
+	}
+	p.trimTrailingSpace()
+	p.add(Span{Text: "\n"})
+}
+
+func (p *parser) image(n *html.Node) {
+	src := strings.TrimSpace(attr(n, "src"))
+	if src == "" {
+		src = strings.TrimSpace(attr(n, "data-src"))
+	}
+	if src == "" {
+		return
+	}
+	img := &Image{Src: src, Alt: strings.TrimSpace(collapse(attr(n, "alt"))), Title: strings.TrimSpace(collapse(attr(n, "title")))}
+	p.article.Images = append(p.article.Images, *img)
+	p.add(Span{Image: img, Link: p.link})
+}
+
+// add appends a span to the current paragraph, merging it with the previous one if they look the same
+func (p *parser) add(s Span) {
+	para := p.start()
+	if n := len(para.Spans); n > 0 && s.Image == nil && s.Text != "\n" {
+		last := &para.Spans[n-1]
+		if last.Image == nil && last.Text != "\n" && last.Emphasis == s.Emphasis && last.Strong == s.Strong &&
+			last.Code == s.Code && last.Link == s.Link {
+			last.Text += s.Text
+			return
+		}
+	}
+	para.Spans = append(para.Spans, s)
+}
+
+func (p *parser) trimTrailingSpace() {
+	spans := p.cur.Spans
+	for len(spans) > 0 {
+		last := &spans[len(spans)-1]
+		if last.Image != nil {
+			break
+		}
+		last.Text = strings.TrimRight(last.Text, " \n")
+		if last.Text != "" {
+			break
+		}
+		spans = spans[:len(spans)-1]
+	}
+	p.cur.Spans = spans
+}
+
+// flush ends the current paragraph, dropping it if it's empty
+func (p *parser) flush() {
+	if p.cur == nil {
+		return
+	}
+	if p.cur.Kind == Code {
+		text := strings.TrimPrefix(p.cur.Text(), "\n")
+		p.cur.Spans = []Span{{Text: strings.TrimRight(text, " \t\n")}}
+	} else {
+		p.trimTrailingSpace()
+	}
+	if len(p.cur.Spans) > 0 && (p.cur.Spans[0].Text != "" || p.cur.Spans[0].Image != nil) {
+		p.article.Paragraphs = append(p.article.Paragraphs, *p.cur)
+	}
+	p.cur = nil
+}
+
+// findByline looks for the author in the meta tags, then for an element marked as the byline
+func findByline(doc *html.Node) (string, *html.Node) {
+	meta := find(doc, func(n *html.Node) bool {
+		return n.DataAtom == atom.Meta && strings.EqualFold(attr(n, "name"), "author") && attr(n, "content") != ""
+	})
+	if meta != nil {
+		return strings.TrimSpace(collapse(attr(meta, "content"))), nil
+	}
+	n := find(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode || n.DataAtom == atom.Meta {
+			return false
+		}
+		if attr(n, "rel") == "author" {
+			return true
+		}
+		for _, class := range strings.Fields(attr(n, "class")) {
+			if class == "byline" || class == "author" {
+				return true
+			}
+		}
+		return false
+	})
+	if n == nil {
+		return "", nil
+	}
+	byline := strings.TrimSpace(collapse(textOf(n)))
+	if len(byline) > 3 && strings.EqualFold(byline[:3], "by ") {
+		byline = strings.TrimSpace(byline[3:])
+	}
+	return byline, n
+}
+
+func find(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if n.Type == html.ElementNode && match(n) {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := find(c, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func textOf(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textOf(c))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// collapse turns every run of white space into a single space
+func collapse(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\f':
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
@@ -0,0 +1,248 @@
+package article
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PlainText renders the article as plain text: the title, the byline and the paragraphs separated by blank lines.
+// List items keep their bullet or number, images are left out
+func (a *Article) PlainText() string {
+	var blocks []string
+	for _, s := range []string{a.Title, a.Byline} {
+		if s != "" {
+			blocks = append(blocks, s)
+		}
+	}
+	var kinds []Kind
+	for _, p := range a.Paragraphs {
+		var text string
+		switch p.Kind {
+		case Rule:
+			continue
+		case ListItem:
+			// continuation lines line up with the text after the marker
+			indent := strings.Repeat("  ", p.Level)
+			text = indent + marker(p) + strings.Replace(p.Text(), "\n", "\n"+indent+strings.Repeat(" ", len(marker(p))), -1)
+		default:
+			text = p.Text()
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		blocks = append(blocks, text)
+		kinds = append(kinds, p.Kind)
+	}
+	return join(blocks, kinds, len(blocks)-len(kinds), func(Kind, Kind) string { return "\n\n" }) + "\n"
+}
+
+// Markdown renders the article as CommonMark, the title as a level 1 heading followed by the byline
+func (a *Article) Markdown() string {
+	var blocks []string
+	if a.Title != "" {
+		blocks = append(blocks, "# "+escapeLine(escape(a.Title)))
+	}
+	if a.Byline != "" {
+		blocks = append(blocks, "*"+escape(a.Byline)+"*")
+	}
+	var kinds []Kind
+	for _, p := range a.Paragraphs {
+		block := markdownBlock(p)
+		if block == "" {
+			continue
+		}
+		blocks = append(blocks, block)
+		kinds = append(kinds, p.Kind)
+	}
+	separator := func(prev, cur Kind) string {
+		if prev == Quote && cur == Quote {
+			return "\n>\n"
+		}
+		return "\n\n"
+	}
+	return join(blocks, kinds, len(blocks)-len(kinds), separator) + "\n"
+}
+
+// join separates the blocks, list items only by a line break. The first header blocks have no kind
+func join(blocks []string, kinds []Kind, header int, separator func(prev, cur Kind) string) string {
+	var b strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			switch {
+			case i <= header:
+				b.WriteString("\n\n")
+			case kinds[i-header-1] == ListItem && kinds[i-header] == ListItem:
+				b.WriteString("\n")
+			default:
+				b.WriteString(separator(kinds[i-header-1], kinds[i-header]))
+			}
+		}
+		b.WriteString(block)
+	}
+	return b.String()
+}
+
+func marker(p Paragraph) string {
+	if p.Number > 0 {
+		return strconv.Itoa(p.Number) + ". "
+	}
+	return "- "
+}
+
+func markdownBlock(p Paragraph) string {
+	switch p.Kind {
+	case Rule:
+		return "---"
+	case Code:
+		text := p.Text()
+		fence := strings.Repeat("`", maxInt(3, longestRun(text, '`')+1))
+		return fence + "\n" + text + "\n" + fence
+	}
+	spans := p.Spans
+	if p.Kind == Heading {
+		// a heading is a single line
+		spans = append([]Span(nil), spans...)
+		for i := range spans {
+			if spans[i].Text == "\n" {
+				spans[i].Text = " "
+			}
+		}
+	}
+	text := markdownSpans(spans)
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = escapeLine(lines[i])
+	}
+	switch p.Kind {
+	case Heading:
+		return strings.Repeat("#", p.Level) + " " + lines[0]
+	case ListItem:
+		// four spaces per level nest an item under any list marker up to "10. "
+		indent := strings.Repeat("    ", p.Level)
+		return indent + marker(p) + strings.Join(lines, "\n"+indent+strings.Repeat(" ", len(marker(p))))
+	case Quote:
+		return "> " + strings.Join(lines, "\n> ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownSpans renders the spans inline, a line break becomes a backslash hard break
+func markdownSpans(spans []Span) string {
+	var b strings.Builder
+	for i := 0; i < len(spans); {
+		// consecutive spans of the same link make a single link
+		j := i + 1
+		for j < len(spans) && spans[j].Link == spans[i].Link {
+			j++
+		}
+		var inner strings.Builder
+		for _, s := range spans[i:j] {
+			inner.WriteString(markdownSpan(s))
+		}
+		if link := spans[i].Link; link != "" {
+			text := inner.String()
+			lead, trail := text[:len(text)-len(strings.TrimLeft(text, " "))], text[len(strings.TrimRight(text, " ")):]
+			b.WriteString(lead + "[" + strings.TrimSpace(text) + "](" + destination(link) + ")" + trail)
+		} else {
+			b.WriteString(inner.String())
+		}
+		i = j
+	}
+	return b.String()
+}
+
+func markdownSpan(s Span) string {
+	if s.Image != nil {
+		img := "![" + escape(s.Image.Alt) + "](" + destination(s.Image.Src)
+		if s.Image.Title != "" {
+			img += ` "` + strings.Replace(escape(s.Image.Title), `"`, `\"`, -1) + `"`
+		}
+		return img + ")"
+	}
+	if s.Text == "\n" {
+		return "\\\n"
+	}
+	// delimiters must hug the text, the surrounding spaces go outside
+	text := strings.TrimSpace(s.Text)
+	if text == "" {
+		return s.Text
+	}
+	lead := s.Text[:strings.Index(s.Text, text)]
+	trail := s.Text[len(lead)+len(text):]
+	if s.Code {
+		text = codeSpan(text)
+	} else {
+		text = escape(text)
+	}
+	if s.Emphasis {
+		text = "*" + text + "*"
+	}
+	if s.Strong {
+		text = "**" + text + "**"
+	}
+	return lead + text + trail
+}
+
+func codeSpan(text string) string {
+	fence := strings.Repeat("`", longestRun(text, '`')+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return fence + text + fence
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`,
+)
+
+// escape makes sure text is read literally
+func escape(text string) string {
+	return escaper.Replace(text)
+}
+
+var orderedMarker = regexp.MustCompile(`^(\d{1,9})([.)])`)
+
+// escapeLine escapes what would start a block at the beginning of a line
+func escapeLine(line string) string {
+	if m := orderedMarker.FindStringSubmatch(line); m != nil {
+		return m[1] + `\` + line[len(m[1]):]
+	}
+	if line != "" && strings.ContainsRune("#-+=|", rune(line[0])) {
+		return `\` + line
+	}
+	return line
+}
+
+// destination renders a link destination, in angle brackets if it has spaces or parentheses
+func destination(url string) string {
+	if strings.ContainsAny(url, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(url) + ">"
+	}
+	return url
+}
+
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+	"golang.org/x/term"
+)
+
+const userAgent = "instapaper-go-client-cli"
+
+// client returns an authenticated client, using the saved tokens when there are any
+func (a *app) client(ctx context.Context) (*instapaper.Client, error) {
+	if a.cfg.ConsumerKey == "" || a.cfg.ConsumerSecret == "" {
+		return nil, fmt.Errorf("no consumer key and secret, set them in %s or through %s and %s",
+			a.cfg.path, envConsumerKey, envConsumerSecret)
+	}
+	var store instapaper.TokenStore = &instapaper.FileTokenStore{Path: a.cfg.tokenPath()}
+	if os.Getenv(instapaper.DefaultTokenEnvVar) != "" {
+		store = &instapaper.EnvTokenStore{}
+	}
+	credentials, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if credentials == nil && a.cfg.Username == "" {
+		return nil, errors.New("not logged in, run instapaper login first")
+	}
+	client, err := instapaper.NewClient(a.cfg.ConsumerKey, a.cfg.ConsumerSecret, a.cfg.Username, a.cfg.Password,
+		a.clientOptions(
+			instapaper.WithTokenStore(store),
+			instapaper.WithRetryPolicy(instapaper.DefaultRetryPolicy),
+		)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return client, client.AuthenticateContext(ctx)
+}
+
+// clientOptions adds the options every client of the tool needs to opts
+func (a *app) clientOptions(opts ...instapaper.Option) []instapaper.Option {
+	opts = append(opts, instapaper.WithUserAgent(userAgent))
+	if a.cfg.BaseURL != "" {
+		opts = append(opts, instapaper.WithBaseURL(a.cfg.BaseURL))
+	}
+	return opts
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid ID %q", s)
+	}
+	return id, nil
+}
+
+func (a *app) login(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return a.usage("login")
+	}
+	if a.cfg.ConsumerKey == "" || a.cfg.ConsumerSecret == "" {
+		return fmt.Errorf("no consumer key and secret, set them in %s or through %s and %s",
+			a.cfg.path, envConsumerKey, envConsumerSecret)
+	}
+	input := bufio.NewReader(a.stdin)
+	prompt := func(label string, value *string) error {
+		if *value != "" {
+			return nil
+		}
+		fmt.Fprintf(a.stderr, "%s: ", label)
+		line, err := input.ReadString('\n')
+		if err != nil && line == "" {
+			return err
+		}
+		*value = strings.TrimRight(line, "\r\n")
+		return nil
+	}
+	// the password isn't echoed when typed in a terminal
+	promptPassword := func(label string, value *string) error {
+		f, ok := a.stdin.(*os.File)
+		if *value != "" || !ok || !term.IsTerminal(int(f.Fd())) {
+			return prompt(label, value)
+		}
+		fmt.Fprintf(a.stderr, "%s: ", label)
+		password, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(a.stderr)
+		if err != nil {
+			return err
+		}
+		*value = string(password)
+		return nil
+	}
+	username, password := a.cfg.Username, a.cfg.Password
+	if err := prompt("Username", &username); err != nil {
+		return err
+	}
+	if err := promptPassword("Password (leave empty if the account has none)", &password); err != nil {
+		return err
+	}
+	client, err := instapaper.NewClient(a.cfg.ConsumerKey, a.cfg.ConsumerSecret, username, password,
+		a.clientOptions(instapaper.WithVerifyOnAuthenticate())...,
+	)
+	if err != nil {
+		return err
+	}
+	if err := client.AuthenticateContext(ctx); err != nil {
+		return err
+	}
+	store := &instapaper.FileTokenStore{Path: a.cfg.tokenPath()}
+	if err := store.Save(client.Credentials); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Logged in as %s, tokens saved to %s\n", client.User.Username, store.Path)
+	return nil
+}
+
+func bookmarkTable(bookmarks []instapaper.Bookmark) table {
+	t := table{
+		headers: []string{"ID", "TITLE", "URL", "PROGRESS", "STARRED", "SAVED"},
+		value:   bookmarks,
+	}
+	if bookmarks == nil {
+		t.value = []instapaper.Bookmark{}
+	}
+	for _, b := range bookmarks {
+		starred := ""
+		if b.Starred == "1" {
+			starred = "yes"
+		}
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(b.ID),
+			b.Title,
+			b.URL,
+			fmt.Sprintf("%.0f%%", b.Progress*100),
+			starred,
+			time.Unix(int64(b.Time), 0).Format("2006-01-02"),
+		})
+	}
+	return t
+}
+
+func (a *app) listBookmarks(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("ls", flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	limit := flags.Int("limit", 0, "list at most this many bookmarks, 0 lists every bookmark in the folder")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 1 {
+		return a.usage("ls [-limit n] [folder]")
+	}
+	folder := instapaper.FolderIDUnread
+	if flags.NArg() == 1 {
+		folder = flags.Arg(0)
+	}
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	var bookmarks []instapaper.Bookmark
+	it := client.Bookmarks.ListAll(ctx, folder)
+	for (*limit <= 0 || len(bookmarks) < *limit) && it.Next() {
+		bookmarks = append(bookmarks, it.Bookmark())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	return printTable(a.stdout, a.format, bookmarkTable(bookmarks))
+}
+
+func (a *app) addBookmark(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("add", flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	title := flags.String("title", "", "title of the bookmark, Instapaper figures it out when empty")
+	description := flags.String("description", "", "description of the bookmark")
+	folder := flags.String("folder", "", "folder to save the bookmark into")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return a.usage("add [-title t] [-description d] [-folder f] <url>")
+	}
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	bookmark, err := client.Bookmarks.AddContext(ctx, instapaper.BookmarkAddRequestParams{
+		URL:             flags.Arg(0),
+		Title:           *title,
+		Description:     *description,
+		Folder:          *folder,
+		ResolveFinalURL: true,
+	})
+	if err != nil {
+		return err
+	}
+	return printTable(a.stdout, a.format, bookmarkTable([]instapaper.Bookmark{*bookmark}))
+}
+
+func (a *app) bookmarkAction(ctx context.Context, command string, args []string) error {
+	if len(args) != 1 {
+		return a.usage(command + " <id>")
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	actions := map[string]func(context.Context, int) error{
+		"archive":   client.Bookmarks.ArchiveContext,
+		"unarchive": client.Bookmarks.UnArchiveContext,
+		"star":      client.Bookmarks.StarContext,
+		"unstar":    client.Bookmarks.UnStarContext,
+		"rm":        client.Bookmarks.DeletePermanentlyContext,
+	}
+	return actions[command](ctx, id)
+}
+
+func (a *app) moveBookmark(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return a.usage("move <id> <folder>")
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.Bookmarks.MoveContext(ctx, id, args[1])
+}
+
+func (a *app) text(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return a.usage("text <id>")
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	client, err := a.client(ctx)
+	if err != nil {
+		return err
+	}
+	text, err := client.Bookmarks.GetTextContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(a.stdout, text)
+	return err
+}
+
+func folderTable(folders []instapaper.Folder) table {
+	t := table{
+		headers: []string{"ID", "TITLE", "POSITION", "BUILT-IN"},
+		value:   folders,
+	}
+	if folders == nil {
+		t.value = []instapaper.Folder{}
+	}
+	for _, f := range folders {
+		builtIn := ""
+		if f.BuiltIn {
+			builtIn = "yes"
+		}
+		t.rows = append(t.rows, []string{f.ID.String(), f.Title, f.Position.String(), builtIn})
+	}
+	return t
+}
+
+func (a *app) folders(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return a.usage("folders ls|add|rm|order")
+	}
+	subcommand, args := args[0], args[1:]
+	var svc *instapaper.FolderService
+	connect := func() error {
+		client, err := a.client(ctx)
+		if err != nil {
+			return err
+		}
+		svc = client.Folders
+		return nil
+	}
+	switch subcommand {
+	case "ls":
+		if len(args) != 0 {
+			return a.usage("folders ls")
+		}
+		if err := connect(); err != nil {
+			return err
+		}
+		folders, err := svc.ListAllContext(ctx)
+		if err != nil {
+			return err
+		}
+		return printTable(a.stdout, a.format, folderTable(folders))
+	case "add":
+		if len(args) != 1 {
+			return a.usage("folders add <title>")
+		}
+		if err := connect(); err != nil {
+			return err
+		}
+		folder, err := svc.AddContext(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return printTable(a.stdout, a.format, folderTable([]instapaper.Folder{*folder}))
+	case "rm":
+		if len(args) != 1 {
+			return a.usage("folders rm <folder>")
+		}
+		if err := connect(); err != nil {
+			return err
+		}
+		return svc.DeleteContext(ctx, args[0])
+	case "order":
+		if len(args) == 0 {
+			return a.usage("folders order <folder>...")
+		}
+		pairs := make([]string, len(args))
+		for i, folderID := range args {
+			pairs[i] = fmt.Sprintf("%s:%d", folderID, i+1)
+		}
+		if err := connect(); err != nil {
+			return err
+		}
+		folders, err := svc.SetOrderContext(ctx, strings.Join(pairs, ","))
+		if err != nil {
+			return err
+		}
+		return printTable(a.stdout, a.format, folderTable(folders))
+	}
+	return a.usage("folders ls|add|rm|order")
+}
+
+func highlightTable(highlights []instapaper.Highlight) table {
+	t := table{
+		headers: []string{"ID", "BOOKMARK", "POSITION", "TEXT", "NOTE"},
+		value:   highlights,
+	}
+	if highlights == nil {
+		t.value = []instapaper.Highlight{}
+	}
+	for _, h := range highlights {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(h.ID),
+			strconv.Itoa(h.BookmarkID),
+			strconv.Itoa(h.Position),
+			h.Text,
+			h.Note,
+		})
+	}
+	return t
+}
+
+func (a *app) highlights(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return a.usage("highlights ls|add|rm")
+	}
+	subcommand, args := args[0], args[1:]
+	var svc *instapaper.HighlightService
+	connect := func() error {
+		client, err := a.client(ctx)
+		if err != nil {
+			return err
+		}
+		svc = client.Highlights
+		return nil
+	}
+	switch subcommand {
+	case "ls":
+		if len(args) != 1 {
+			return a.usage("highlights ls <id>")
+		}
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		if err := connect(); err != nil {
+			return err
+		}
+		highlights, err := svc.ListContext(ctx, id)
+		if err != nil {
+			return err
+		}
+		return printTable(a.stdout, a.format, highlightTable(highlights))
+	case "add":
+		flags := flag.NewFlagSet("highlights add", flag.ContinueOnError)
+		flags.SetOutput(a.stderr)
+		position := flags.Int("position", 0, "position of the highlight in the text")
+		if err := flags.Parse(args); err != nil || flags.NArg() < 1 || flags.NArg() > 2 {
+			return a.usage("highlights add [-position n] <id> [text]")
+		}
+		id, err := parseID(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		text := flags.Arg(1)
+		if flags.NArg() == 1 {
+			// no text argument, read it from stdin - handy for long quotes
+			data, err := ioutil.ReadAll(a.stdin)
+			if err != nil {
+				return err
+			}
+			text = strings.TrimSpace(string(data))
+		}
+		if err := connect(); err != nil {
+			return err
+		}
+		highlight, err := svc.AddContext(ctx, id, text, *position)
+		if err != nil {
+			return err
+		}
+		return printTable(a.stdout, a.format, highlightTable([]instapaper.Highlight{*highlight}))
+	case "rm":
+		if len(args) != 1 {
+			return a.usage("highlights rm <highlight id>")
+		}
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		if err := connect(); err != nil {
+			return err
+		}
+		return svc.DeleteContext(ctx, id)
+	}
+	return a.usage("highlights ls|add|rm")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Environment variables overriding the config file
+const (
+	envConsumerKey    = "INSTAPAPER_CONSUMER_KEY"
+	envConsumerSecret = "INSTAPAPER_CONSUMER_SECRET"
+	envUsername       = "INSTAPAPER_USERNAME"
+	envPassword       = "INSTAPAPER_PASSWORD"
+)
+
+// config holds the credentials the tool needs. The password is only needed by login, after that the OAuth tokens
+// saved next to the config file are used
+type config struct {
+	ConsumerKey    string `json:"consumer_key"`
+	ConsumerSecret string `json:"consumer_secret"`
+	Username       string `json:"username,omitempty"`
+	Password       string `json:"password,omitempty"`
+	// BaseURL overrides the API root, e.g. to go through a proxy
+	BaseURL string `json:"base_url,omitempty"`
+
+	// path is where the config was loaded from, the token file lives next to it
+	path string
+}
+
+// defaultConfigPath returns ~/.config/instapaper/config.json or the platform's equivalent
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "instapaper", "config.json")
+}
+
+// loadConfig reads the config file - a missing one is fine - and applies the environment on top of it
+func loadConfig(path string) (*config, error) {
+	cfg := &config{path: path}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, err
+		}
+	}
+	override := func(field *string, name string) {
+		if value := os.Getenv(name); value != "" {
+			*field = value
+		}
+	}
+	override(&cfg.ConsumerKey, envConsumerKey)
+	override(&cfg.ConsumerSecret, envConsumerSecret)
+	override(&cfg.Username, envUsername)
+	override(&cfg.Password, envPassword)
+	return cfg, nil
+}
+
+// tokenPath is where login saves the OAuth tokens
+func (c *config) tokenPath() string {
+	return filepath.Join(filepath.Dir(c.path), "token.json")
+}
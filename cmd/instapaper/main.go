@@ -0,0 +1,127 @@
+// Command instapaper manages an Instapaper account from the command line.
+//
+// Usage:
+//
+//	instapaper [-config file] [-o table|json|tsv] <command> [arguments]
+//
+// The commands are:
+//
+//	login                                               authenticate and save the OAuth tokens
+//	ls [-limit n] [folder]                              list bookmarks, unread by default
+//	add [-title t] [-description d] [-folder f] <url>   save a URL
+//	archive|unarchive <id>                              (un)archive a bookmark
+//	star|unstar <id>                                    (un)star a bookmark
+//	move <id> <folder>                                  move a bookmark to a folder
+//	rm <id>                                             permanently delete a bookmark
+//	text <id>                                           print the text-view HTML of a bookmark
+//	folders ls                                          list every folder, built-in ones included
+//	folders add <title>                                 create a folder
+//	folders rm <folder>                                 delete a folder
+//	folders order <folder>...                           reorder the custom folders
+//	highlights ls <id>                                  list the highlights of a bookmark
+//	highlights add [-position n] <id> [text]            add a highlight, the text is read from stdin if omitted
+//	highlights rm <highlight id>                        delete a highlight
+//
+// The consumer key and secret - and for login the username and password - come from the config file
+// (~/.config/instapaper/config.json by default) or the INSTAPAPER_CONSUMER_KEY, INSTAPAPER_CONSUMER_SECRET,
+// INSTAPAPER_USERNAME and INSTAPAPER_PASSWORD environment variables. login saves the OAuth tokens to token.json next to
+// the config file, INSTAPAPER_OAUTH_TOKEN and INSTAPAPER_OAUTH_TOKEN_SECRET take precedence over it.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+// errUsage signals a command line mistake, the usage has been printed already
+var errUsage = errors.New("usage error")
+
+// app carries the global options to the commands
+type app struct {
+	cfg    *config
+	format string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		cancel()
+	}()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("instapaper", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", defaultConfigPath(), "path of the config file")
+	format := flags.String("o", formatTable, "output format: table, json or tsv")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "usage: instapaper [-config file] [-o table|json|tsv] <command> [arguments]")
+		fmt.Fprintln(stderr, "commands: login, ls, add, archive, unarchive, star, unstar, move, rm, text, folders, highlights")
+		return 2
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "instapaper: reading config: %v\n", err)
+		return 1
+	}
+	a := &app{
+		cfg:    cfg,
+		format: *format,
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+	err = a.dispatch(ctx, flags.Arg(0), flags.Args()[1:])
+	if err == errUsage {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "instapaper: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (a *app) dispatch(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "login":
+		return a.login(ctx, args)
+	case "ls":
+		return a.listBookmarks(ctx, args)
+	case "add":
+		return a.addBookmark(ctx, args)
+	case "archive", "unarchive", "star", "unstar", "rm":
+		return a.bookmarkAction(ctx, command, args)
+	case "move":
+		return a.moveBookmark(ctx, args)
+	case "text":
+		return a.text(ctx, args)
+	case "folders":
+		return a.folders(ctx, args)
+	case "highlights":
+		return a.highlights(ctx, args)
+	}
+	fmt.Fprintf(a.stderr, "instapaper: unknown command %q\n", command)
+	return errUsage
+}
+
+// usage prints the usage line of a command and returns errUsage
+func (a *app) usage(line string) error {
+	fmt.Fprintf(a.stderr, "usage: instapaper %s\n", line)
+	return errUsage
+}
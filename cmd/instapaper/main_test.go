@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrintTable(t *testing.T) {
+	tbl := table{
+		headers: []string{"ID", "TITLE"},
+		rows:    [][]string{{"1", "tab\there"}},
+		value:   []map[string]string{{"id": "1"}},
+	}
+	cases := map[string]string{
+		formatTSV:  "ID\tTITLE\n1\ttab\\there\n",
+		formatJSON: "[\n  {\n    \"id\": \"1\"\n  }\n]\n",
+		formatTable: "ID  TITLE\n" +
+			"1   tab here\n",
+	}
+	for format, expected := range cases {
+		var buf bytes.Buffer
+		if err := printTable(&buf, format, tbl); err != nil {
+			t.Errorf("%v: expected err to be nil, got %v", format, err)
+		}
+		if buf.String() != expected {
+			t.Errorf("%v: expected %q, got %q", format, expected, buf.String())
+		}
+	}
+	if err := printTable(&bytes.Buffer{}, "xml", tbl); err == nil {
+		t.Errorf("expected an unknown format to fail")
+	}
+}
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bookmarks/list":
+			fmt.Fprint(w, `{"bookmarks":[{"bookmark_id":1,"title":"First","url":"https://example.com","progress":0.5,"starred":"1"}]}`)
+		case "/bookmarks/star":
+			if r.FormValue("bookmark_id") != "1" {
+				t.Errorf("expected bookmark 1 to be starred, got %v", r.FormValue("bookmark_id"))
+			}
+			fmt.Fprint(w, `[]`)
+		default:
+			t.Errorf("unexpected call to %v", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "instapaper-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config.json")
+	config := fmt.Sprintf(`{"consumer_key":"key","consumer_secret":"secret","base_url":%q}`, server.URL)
+	if err := ioutil.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "token.json"), []byte(`{"oauth_token":"t","oauth_token_secret":"s"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-config", configPath, "-o", "tsv", "ls"}, strings.NewReader(""), &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %v", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "1\tFirst\thttps://example.com\t50%\tyes\t") {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+
+	code = run(context.Background(), []string{"-config", configPath, "star", "1"}, strings.NewReader(""), &stdout, &stderr)
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d: %v", code, stderr.String())
+	}
+	code = run(context.Background(), []string{"-config", configPath, "star", "nope"}, strings.NewReader(""), &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("expected exit code 1 for an invalid ID, got %d", code)
+	}
+	code = run(context.Background(), []string{"-config", configPath, "frobnicate"}, strings.NewReader(""), &stdout, &stderr)
+	if code != 2 {
+		t.Errorf("expected exit code 2 for an unknown command, got %d", code)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatTSV   = "tsv"
+)
+
+// table is a printable result: JSON output uses value, the other formats the headers and rows
+type table struct {
+	headers []string
+	rows    [][]string
+	value   interface{}
+}
+
+func printTable(w io.Writer, format string, t table) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(t.value)
+	case formatTSV:
+		for _, row := range append([][]string{t.headers}, t.rows...) {
+			escaped := make([]string, len(row))
+			for i, cell := range row {
+				escaped[i] = tsvEscaper.Replace(cell)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(escaped, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, row := range append([][]string{t.headers}, t.rows...) {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = truncate(collapseSpace(cell), 60)
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown output format %q, use %s, %s or %s", format, formatTable, formatJSON, formatTSV)
+}
+
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncate shortens s to at most width runes, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,341 @@
+// Package epub builds EPUB 3 digests of saved articles for reading offline on e-readers.
+//
+// The processed text of every article (see BookmarkService.GetText) is sanitized into XHTML, its images are downloaded
+// and embedded, and the articles are bound together with a table of contents:
+//
+//	b := &epub.Builder{Title: "Unread articles"}
+//	articles, err := b.Fetch(ctx, client, bookmarks)
+//	...
+//	err = b.Build(ctx, file, articles)
+package epub
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// defaultMaxImageSize keeps a single huge image from bloating the book
+const defaultMaxImageSize = 5 << 20
+
+// imageTypes maps the supported image media types to file extensions
+var imageTypes = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+}
+
+const stylesheet = `body { font-family: serif; line-height: 1.5; }
+h1 { font-size: 1.5em; margin-bottom: 0.2em; }
+p.source { font-size: 0.8em; color: #555; margin-top: 0; }
+blockquote { margin-left: 1em; font-style: italic; }
+img { max-width: 100%; height: auto; }
+pre { white-space: pre-wrap; }
+`
+
+// ErrNoArticles is returned when there's nothing to put in the book, an EPUB needs at least one chapter
+var ErrNoArticles = errors.New("epub: no articles")
+
+// Article is a bookmark along with its text-view HTML
+type Article struct {
+	Bookmark instapaper.Bookmark
+	HTML     string
+}
+
+// Builder holds the settings of the book. The zero value is usable
+type Builder struct {
+	// Title of the book, defaults to "Instapaper" and the current date
+	Title string
+	// Author defaults to "Instapaper"
+	Author string
+	// Language is a BCP 47 language tag, defaults to "en"
+	Language string
+	// HTTPClient downloads the images, http.DefaultClient is used when it's nil
+	HTTPClient *http.Client
+	// SkipImages leaves images out of the book entirely
+	SkipImages bool
+	// MaxImageSize is the size limit of a single image in bytes, larger ones are left out. Defaults to 5MB
+	MaxImageSize int64
+}
+
+// Fetch downloads the text of the bookmarks. Bookmarks Instapaper can't make a text version of (ErrTextGen) are skipped,
+// ErrNoArticles is returned if that's all of them
+func (b *Builder) Fetch(ctx context.Context, client *instapaper.Client, bookmarks []instapaper.Bookmark) ([]Article, error) {
+	var articles []Article
+	for _, bookmark := range bookmarks {
+		text, err := client.Bookmarks.GetTextContext(ctx, bookmark.ID)
+		if apiErr, ok := err.(*instapaper.APIError); ok && apiErr.ErrorCode == instapaper.ErrTextGen {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, Article{Bookmark: bookmark, HTML: text})
+	}
+	if len(articles) == 0 {
+		return nil, ErrNoArticles
+	}
+	return articles, nil
+}
+
+// chapter is an article turned into a page of the book
+type chapter struct {
+	title string
+	file  string
+	body  string
+}
+
+// image is an embedded image
+type image struct {
+	file      string
+	mediaType string
+	data      []byte
+}
+
+// zipEntry is a text file of the book
+type zipEntry struct {
+	name    string
+	content string
+}
+
+// Build writes the book made of the articles to w, or returns ErrNoArticles if there are none
+func (b *Builder) Build(ctx context.Context, w io.Writer, articles []Article) error {
+	if len(articles) == 0 {
+		return ErrNoArticles
+	}
+	title := b.Title
+	if title == "" {
+		title = "Instapaper " + time.Now().Format("2006-01-02")
+	}
+	author := b.Author
+	if author == "" {
+		author = "Instapaper"
+	}
+	language := b.Language
+	if language == "" {
+		language = "en"
+	}
+
+	images := map[string]*image{}
+	var imageOrder []*image
+	resolveImage := func(src string) string {
+		if b.SkipImages {
+			return ""
+		}
+		if img, ok := images[src]; ok {
+			if img == nil {
+				return ""
+			}
+			return "../" + img.file
+		}
+		img := b.download(ctx, src, len(imageOrder)+1)
+		images[src] = img
+		if img == nil {
+			return ""
+		}
+		imageOrder = append(imageOrder, img)
+		return "../" + img.file
+	}
+
+	var chapters []chapter
+	for i, article := range articles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		base, _ := url.Parse(article.Bookmark.URL)
+		body, err := sanitize(article.HTML, base, resolveImage)
+		if err != nil {
+			return err
+		}
+		articleTitle := article.Bookmark.Title
+		if articleTitle == "" {
+			articleTitle = article.Bookmark.URL
+		}
+		chapters = append(chapters, chapter{
+			title: articleTitle,
+			file:  fmt.Sprintf("articles/article-%03d.xhtml", i+1),
+			body:  chapterPage(articleTitle, article.Bookmark.URL, body, language),
+		})
+	}
+
+	z := zip.NewWriter(w)
+	// the mimetype file has to come first and uncompressed
+	mimetype, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return err
+	}
+	files := []zipEntry{
+		{"META-INF/container.xml", containerXML},
+		{"OEBPS/content.opf", packageDocument(title, author, language, newUUID(), chapters, imageOrder)},
+		{"OEBPS/nav.xhtml", navDocument(title, language, chapters)},
+		{"OEBPS/toc.ncx", ncxDocument(title, chapters)},
+		{"OEBPS/style.css", stylesheet},
+	}
+	for _, c := range chapters {
+		files = append(files, zipEntry{"OEBPS/" + c.file, c.body})
+	}
+	for _, f := range files {
+		fw, err := z.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	for _, img := range imageOrder {
+		fw, err := z.Create("OEBPS/" + img.file)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(img.data); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// download fetches an image, returning nil if it can't be embedded for any reason - the book is still fine without it
+func (b *Builder) download(ctx context.Context, src string, n int) *image {
+	req, err := http.NewRequest(http.MethodGet, src, nil)
+	if err != nil {
+		return nil
+	}
+	client := b.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	ext, ok := imageTypes[mediaType]
+	if !ok {
+		return nil
+	}
+	maxSize := b.MaxImageSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxImageSize
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(res.Body, maxSize+1))
+	if err != nil || int64(len(data)) > maxSize {
+		return nil
+	}
+	return &image{
+		file:      path.Join("images", fmt.Sprintf("image-%03d%s", n, ext)),
+		mediaType: mediaType,
+		data:      data,
+	}
+}
+
+// newUUID returns a random (version 4) UUID for the book's identifier
+func newUUID() string {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+func chapterPage(title, source, body, language string) string {
+	var s strings.Builder
+	s.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	s.WriteString("<!DOCTYPE html>\n")
+	fmt.Fprintf(&s, `<html xmlns="http://www.w3.org/1999/xhtml" lang="%s" xml:lang="%s">`+"\n", escapeXML(language), escapeXML(language))
+	fmt.Fprintf(&s, "<head>\n<title>%s</title>\n", escapeXML(title))
+	s.WriteString(`<link rel="stylesheet" type="text/css" href="../style.css"/>` + "\n</head>\n<body>\n")
+	fmt.Fprintf(&s, "<h1>%s</h1>\n", escapeXML(title))
+	if href, ok := resolve(nil, source); ok {
+		fmt.Fprintf(&s, `<p class="source"><a href="%s">%s</a></p>`+"\n", escapeXML(href), escapeXML(href))
+	}
+	s.WriteString("<div>" + body + "</div>\n</body>\n</html>\n")
+	return s.String()
+}
+
+func packageDocument(title, author, language, id string, chapters []chapter, images []*image) string {
+	var s strings.Builder
+	s.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	s.WriteString(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">` + "\n")
+	s.WriteString(`  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	fmt.Fprintf(&s, "    <dc:identifier id=\"book-id\">urn:uuid:%s</dc:identifier>\n", id)
+	fmt.Fprintf(&s, "    <dc:title>%s</dc:title>\n", escapeXML(title))
+	fmt.Fprintf(&s, "    <dc:creator>%s</dc:creator>\n", escapeXML(author))
+	fmt.Fprintf(&s, "    <dc:language>%s</dc:language>\n", escapeXML(language))
+	fmt.Fprintf(&s, "    <meta property=\"dcterms:modified\">%s</meta>\n", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	s.WriteString("  </metadata>\n  <manifest>\n")
+	s.WriteString(`    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>` + "\n")
+	s.WriteString(`    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>` + "\n")
+	s.WriteString(`    <item id="style" href="style.css" media-type="text/css"/>` + "\n")
+	for i, c := range chapters {
+		fmt.Fprintf(&s, "    <item id=\"article-%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", i+1, c.file)
+	}
+	for i, img := range images {
+		fmt.Fprintf(&s, "    <item id=\"image-%d\" href=\"%s\" media-type=\"%s\"/>\n", i+1, img.file, img.mediaType)
+	}
+	s.WriteString("  </manifest>\n  <spine toc=\"ncx\">\n")
+	for i := range chapters {
+		fmt.Fprintf(&s, "    <itemref idref=\"article-%d\"/>\n", i+1)
+	}
+	s.WriteString("  </spine>\n</package>\n")
+	return s.String()
+}
+
+func navDocument(title, language string, chapters []chapter) string {
+	var s strings.Builder
+	s.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	s.WriteString("<!DOCTYPE html>\n")
+	fmt.Fprintf(&s, `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="%s" xml:lang="%s">`+"\n",
+		escapeXML(language), escapeXML(language))
+	fmt.Fprintf(&s, "<head>\n<title>%s</title>\n</head>\n<body>\n", escapeXML(title))
+	s.WriteString("<nav epub:type=\"toc\" id=\"toc\">\n<h1>Contents</h1>\n<ol>\n")
+	for _, c := range chapters {
+		fmt.Fprintf(&s, "<li><a href=\"%s\">%s</a></li>\n", c.file, escapeXML(c.title))
+	}
+	s.WriteString("</ol>\n</nav>\n</body>\n</html>\n")
+	return s.String()
+}
+
+// ncxDocument is the EPUB 2 table of contents, older readers only understand this one
+func ncxDocument(title string, chapters []chapter) string {
+	var s strings.Builder
+	s.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	s.WriteString(`<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">` + "\n")
+	s.WriteString("  <head/>\n")
+	fmt.Fprintf(&s, "  <docTitle><text>%s</text></docTitle>\n  <navMap>\n", escapeXML(title))
+	for i, c := range chapters {
+		fmt.Fprintf(&s, "    <navPoint id=\"nav-%d\" playOrder=\"%d\"><navLabel><text>%s</text></navLabel><content src=\"%s\"/></navPoint>\n",
+			i+1, i+1, escapeXML(c.title), c.file)
+	}
+	s.WriteString("  </navMap>\n</ncx>\n")
+	return s.String()
+}
@@ -0,0 +1,163 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gomodule/oauth1/oauth"
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+func TestSanitize(t *testing.T) {
+	base, _ := url.Parse("https://example.com/posts/1")
+	doc := `<div class="x" onclick="evil()"><h2 id="a">Title</h2><p>One &amp; <b>two</b><br>three
+		<script>alert(1)</script><a href="/about" target="_blank">about</a> <a href="javascript:evil()">bad</a>
+		<img src="img.png" width="10"><img src="data:image/png;base64,AAAA"><custom-tag>kept text</custom-tag></p>
+		<iframe src="https://example.com/embed"></iframe><!-- comment --></div>`
+	var resolved []string
+	sanitized, err := sanitize(doc, base, func(src string) string {
+		resolved = append(resolved, src)
+		return "../images/image-001.png"
+	})
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	expected := `<div><h2>Title</h2><p>One &amp; <b>two</b><br/>three
+		<a href="https://example.com/about">about</a> bad
+		<img src="../images/image-001.png" alt=""/>kept text</p>
+		</div>`
+	if sanitized != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, sanitized)
+	}
+	if len(resolved) != 1 || resolved[0] != "https://example.com/posts/img.png" {
+		t.Errorf("expected the image URL to be resolved against the article, got %v", resolved)
+	}
+}
+
+func readZip(t *testing.T, data []byte) map[string][]byte {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("expected a valid zip, got %v", err)
+	}
+	if r.File[0].Name != "mimetype" || r.File[0].Method != zip.Store {
+		t.Errorf("expected an uncompressed mimetype entry first, got %v", r.File[0].FileHeader)
+	}
+	files := map[string][]byte{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = ioutil.ReadAll(rc)
+		rc.Close()
+	}
+	return files
+}
+
+func wellFormed(data []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	images := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pic.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("\x89PNG fake"))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer images.Close()
+
+	articles := []Article{
+		{
+			Bookmark: instapaper.Bookmark{ID: 1, Title: "First & foremost", URL: images.URL + "/one"},
+			HTML:     `<p>Hello <img src="/pic.png"> <img src="/page.html"> <img src="/missing.png"></p>`,
+		},
+		{
+			Bookmark: instapaper.Bookmark{ID: 2, Title: "Second", URL: images.URL + "/two"},
+			HTML:     `<p>Same picture again <img src="/pic.png" alt="pic"></p>`,
+		},
+	}
+	var buf bytes.Buffer
+	b := &Builder{Title: "Digest"}
+	if err := b.Build(context.Background(), &buf, articles); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	files := readZip(t, buf.Bytes())
+	if string(files["mimetype"]) != "application/epub+zip" {
+		t.Errorf("unexpected mimetype %q", files["mimetype"])
+	}
+	for _, name := range []string{"META-INF/container.xml", "OEBPS/content.opf", "OEBPS/nav.xhtml", "OEBPS/toc.ncx",
+		"OEBPS/articles/article-001.xhtml", "OEBPS/articles/article-002.xhtml"} {
+		content, ok := files[name]
+		if !ok {
+			t.Errorf("expected %v to be in the book", name)
+			continue
+		}
+		if err := wellFormed(content); err != nil {
+			t.Errorf("expected %v to be well-formed, got %v", name, err)
+		}
+	}
+	if string(files["OEBPS/images/image-001.png"]) != "\x89PNG fake" {
+		t.Errorf("expected the image to be embedded once, got %v", files)
+	}
+	if len(files) != 9 {
+		t.Errorf("expected only the valid image to be embedded, got %d files", len(files))
+	}
+	first := string(files["OEBPS/articles/article-001.xhtml"])
+	if !strings.Contains(first, `<img src="../images/image-001.png" alt=""/>`) || strings.Count(first, "<img") != 1 {
+		t.Errorf("expected only the embedded image to be referenced, got %v", first)
+	}
+	if nav := string(files["OEBPS/nav.xhtml"]); !strings.Contains(nav, `<a href="articles/article-001.xhtml">First &amp; foremost</a>`) {
+		t.Errorf("expected the articles in the table of contents, got %v", nav)
+	}
+	if opf := string(files["OEBPS/content.opf"]); !strings.Contains(opf, "<dc:title>Digest</dc:title>") ||
+		!strings.Contains(opf, `media-type="image/png"`) {
+		t.Errorf("expected the metadata and the image in the package document, got %v", opf)
+	}
+}
+
+func TestNoArticles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `[{"error_code":%d,"message":"no text"}]`, instapaper.ErrTextGen)
+	}))
+	defer server.Close()
+	client, _ := instapaper.NewClient("client_id", "client_secret", "username", "password", instapaper.WithBaseURL(server.URL))
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+
+	b := &Builder{}
+	articles, err := b.Fetch(context.Background(), client, []instapaper.Bookmark{{ID: 1}, {ID: 2}})
+	if !errors.Is(err, ErrNoArticles) {
+		t.Errorf("expected ErrNoArticles when every text fails, got %v, %v", articles, err)
+	}
+	var buf bytes.Buffer
+	if err := b.Build(context.Background(), &buf, nil); !errors.Is(err, ErrNoArticles) || buf.Len() != 0 {
+		t.Errorf("expected ErrNoArticles and nothing written, got %v, %d bytes", err, buf.Len())
+	}
+}
@@ -0,0 +1,167 @@
+package epub
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedElements survive sanitizing, elements not listed are unwrapped - replaced by their children
+var allowedElements = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true, atom.Div: true, atom.Span: true, atom.Section: true, atom.Article: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Code: true, atom.Q: true, atom.Cite: true, atom.Abbr: true,
+	atom.Em: true, atom.Strong: true, atom.I: true, atom.B: true, atom.U: true, atom.S: true, atom.Small: true,
+	atom.Sub: true, atom.Sup: true, atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.A: true, atom.Img: true, atom.Figure: true, atom.Figcaption: true,
+	atom.Table: true, atom.Thead: true, atom.Tbody: true, atom.Tfoot: true, atom.Tr: true, atom.Th: true, atom.Td: true,
+	atom.Caption: true,
+}
+
+// droppedElements are removed along with everything inside them
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true, atom.Object: true, atom.Embed: true,
+	atom.Form: true, atom.Input: true, atom.Button: true, atom.Select: true, atom.Textarea: true, atom.Svg: true,
+	atom.Video: true, atom.Audio: true, atom.Canvas: true, atom.Head: true, atom.Title: true, atom.Meta: true,
+	atom.Link: true, atom.Template: true,
+}
+
+// allowedAttributes lists the attributes kept per element, everything else is dropped
+var allowedAttributes = map[atom.Atom][]string{
+	atom.A:   {"href"},
+	atom.Img: {"src", "alt"},
+	atom.Td:  {"colspan", "rowspan"},
+	atom.Th:  {"colspan", "rowspan"},
+}
+
+// voidElements have no content and are self-closed in XHTML
+var voidElements = map[atom.Atom]bool{
+	atom.Br:  true,
+	atom.Hr:  true,
+	atom.Img: true,
+}
+
+// imageResolver maps the absolute URL of an image to its path inside the book. An empty result drops the image
+type imageResolver func(src string) string
+
+// sanitize turns the text-view HTML of an article into a well-formed XHTML fragment. Relative links and image sources
+// are resolved against base
+func sanitize(doc string, base *url.URL, images imageResolver) (string, error) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(doc), body)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		writeNode(&b, n, base, images)
+	}
+	return b.String(), nil
+}
+
+func writeNode(b *strings.Builder, n *html.Node, base *url.URL, images imageResolver) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(escapeXML(n.Data))
+		return
+	case html.DocumentNode:
+		writeChildren(b, n, base, images)
+		return
+	case html.ElementNode:
+	default:
+		// comments and doctypes
+		return
+	}
+	if droppedElements[n.DataAtom] {
+		return
+	}
+	if !allowedElements[n.DataAtom] {
+		writeChildren(b, n, base, images)
+		return
+	}
+	attrs := map[string]string{}
+	for _, attr := range n.Attr {
+		for _, allowed := range allowedAttributes[n.DataAtom] {
+			if attr.Namespace == "" && attr.Key == allowed {
+				attrs[attr.Key] = attr.Val
+			}
+		}
+	}
+	switch n.DataAtom {
+	case atom.A:
+		href, ok := resolve(base, attrs["href"])
+		if !ok {
+			// links nowhere or to javascript: - keep the text only
+			writeChildren(b, n, base, images)
+			return
+		}
+		attrs["href"] = href
+	case atom.Img:
+		src, ok := resolve(base, attrs["src"])
+		if !ok {
+			return
+		}
+		local := images(src)
+		if local == "" {
+			return
+		}
+		attrs["src"] = local
+		if _, ok := attrs["alt"]; !ok {
+			attrs["alt"] = ""
+		}
+	}
+
+	b.WriteString("<" + n.Data)
+	for _, key := range allowedAttributes[n.DataAtom] {
+		if value, ok := attrs[key]; ok {
+			b.WriteString(" " + key + `="` + escapeXML(value) + `"`)
+		}
+	}
+	if voidElements[n.DataAtom] {
+		b.WriteString("/>")
+		return
+	}
+	b.WriteString(">")
+	writeChildren(b, n, base, images)
+	b.WriteString("</" + n.Data + ">")
+}
+
+func writeChildren(b *strings.Builder, n *html.Node, base *url.URL, images imageResolver) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeNode(b, c, base, images)
+	}
+}
+
+// resolve makes ref absolute, only http, https and mailto links are accepted
+func resolve(base *url.URL, ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", false
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	switch u.Scheme {
+	case "http", "https", "mailto":
+		return u.String(), true
+	}
+	return "", false
+}
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// escapeXML escapes s for text and attribute values, dropping the characters XML doesn't allow at all
+func escapeXML(s string) string {
+	return xmlEscaper.Replace(strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || (r >= 0x20 && r <= 0xD7FF) || (r >= 0xE000 && r <= 0xFFFD) || (r >= 0x10000 && r <= 0x10FFFF) {
+			return r
+		}
+		return -1
+	}, s))
+}
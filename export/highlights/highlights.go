@@ -0,0 +1,150 @@
+// Package highlights exports highlights along with the bookmark they were made in, as a CSV file Readwise can import or
+// as a Kindle "My Clippings.txt" file most highlight tools understand.
+package highlights
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// Entry is a highlight joined with its bookmark
+type Entry struct {
+	Highlight instapaper.Highlight
+	Bookmark  instapaper.Bookmark
+}
+
+// Time is when the highlight was made, or when the bookmark was saved if the highlight has no time
+func (e Entry) Time() time.Time {
+	if unix, err := e.Highlight.Time.Int64(); err == nil && unix > 0 {
+		return time.Unix(unix, 0).UTC()
+	}
+	if e.Bookmark.Time > 0 {
+		return time.Unix(int64(e.Bookmark.Time), 0).UTC()
+	}
+	return time.Time{}
+}
+
+// Collect returns the highlights of every bookmark in the folder, or in the whole account if folder is empty.
+// Highlights are grouped by bookmark, in the order of their position in the article
+func Collect(ctx context.Context, client *instapaper.Client, folder string) ([]Entry, error) {
+	folders := []string{folder}
+	if folder == "" {
+		all, err := client.Folders.ListAllContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		folders = nil
+		for _, f := range all {
+			// starred bookmarks are listed in the folder they live in as well
+			if f.ID.String() != instapaper.FolderIDStarred {
+				folders = append(folders, f.ID.String())
+			}
+		}
+	}
+	var entries []Entry
+	for _, folderID := range folders {
+		var bookmarks []instapaper.Bookmark
+		it := client.Bookmarks.ListAll(ctx, folderID)
+		for it.Next() {
+			bookmarks = append(bookmarks, it.Bookmark())
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+		byBookmark := map[int][]instapaper.Highlight{}
+		for _, highlight := range it.Highlights() {
+			byBookmark[highlight.BookmarkID] = append(byBookmark[highlight.BookmarkID], highlight)
+		}
+		for _, bookmark := range bookmarks {
+			highlights := byBookmark[bookmark.ID]
+			sort.SliceStable(highlights, func(i, j int) bool {
+				return highlights[i].Position < highlights[j].Position
+			})
+			for _, highlight := range highlights {
+				entries = append(entries, Entry{Highlight: highlight, Bookmark: bookmark})
+			}
+		}
+	}
+	return entries, nil
+}
+
+// readwiseTimeFormat is the date format Readwise's CSV import expects
+const readwiseTimeFormat = "2006-01-02 15:04:05"
+
+// WriteReadwiseCSV writes the entries in Readwise's CSV import format: Highlight, Title, Author, URL, Note, Location
+// and Date columns. Instapaper doesn't know the author, the location is the position of the highlight in the article
+func WriteReadwiseCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Highlight", "Title", "Author", "URL", "Note", "Location", "Date"})
+	for _, e := range entries {
+		date := ""
+		if t := e.Time(); !t.IsZero() {
+			date = t.Format(readwiseTimeFormat)
+		}
+		cw.Write([]string{
+			e.Highlight.Text,
+			title(e.Bookmark),
+			"",
+			e.Bookmark.URL,
+			e.Highlight.Note,
+			strconv.Itoa(e.Highlight.Position),
+			date,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// clippingsSeparator ends every clipping
+const clippingsSeparator = "=========="
+
+// clippingsTimeFormat is how a Kindle writes the time of a clipping
+const clippingsTimeFormat = "Monday, January 2, 2006 3:04:05 PM"
+
+// WriteClippings writes the entries the way a Kindle writes "My Clippings.txt": a title line, a metadata line, the
+// text and a separator, with CRLF line endings. The host of the bookmark's URL stands in for the author, and a note is
+// written as a separate clipping right after its highlight, like a Kindle does
+func WriteClippings(w io.Writer, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("\ufeff")
+	for _, e := range entries {
+		heading := strings.Join(strings.Fields(title(e.Bookmark)), " ")
+		if u, err := url.Parse(e.Bookmark.URL); err == nil && u.Host != "" {
+			heading += " (" + strings.TrimPrefix(u.Host, "www.") + ")"
+		}
+		added := ""
+		if t := e.Time(); !t.IsZero() {
+			added = " | Added on " + t.Format(clippingsTimeFormat)
+		}
+		writeClipping(bw, heading, fmt.Sprintf("- Your Highlight on Location %d%s", e.Highlight.Position, added), e.Highlight.Text)
+		if e.Highlight.Note != "" {
+			writeClipping(bw, heading, fmt.Sprintf("- Your Note on Location %d%s", e.Highlight.Position, added), e.Highlight.Note)
+		}
+	}
+	return bw.Flush()
+}
+
+func writeClipping(w *bufio.Writer, heading, meta, text string) {
+	text = strings.Join(strings.Fields(text), " ")
+	for _, line := range []string{heading, meta, "", text, clippingsSeparator} {
+		w.WriteString(line)
+		w.WriteString("\r\n")
+	}
+}
+
+func title(b instapaper.Bookmark) string {
+	if b.Title != "" {
+		return b.Title
+	}
+	return b.URL
+}
@@ -0,0 +1,110 @@
+package highlights
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gomodule/oauth1/oauth"
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+var entries = []Entry{
+	{
+		Highlight: instapaper.Highlight{ID: 1, BookmarkID: 1, Text: "first,\nquoted \"line\"", Note: "my note", Time: "1601750016", Position: 3},
+		Bookmark:  instapaper.Bookmark{ID: 1, Title: "On Call", URL: "https://www.example.com/a"},
+	},
+	{
+		Highlight: instapaper.Highlight{ID: 2, BookmarkID: 2, Text: "second"},
+		Bookmark:  instapaper.Bookmark{ID: 2, URL: "https://example.com/b", Time: 1601750016},
+	},
+}
+
+func TestWriteReadwiseCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReadwiseCSV(&buf, entries); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	expected := "Highlight,Title,Author,URL,Note,Location,Date\n" +
+		"\"first,\nquoted \"\"line\"\"\",On Call,,https://www.example.com/a,my note,3,2020-10-03 18:33:36\n" +
+		"second,https://example.com/b,,https://example.com/b,,0,2020-10-03 18:33:36\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, buf.String())
+	}
+}
+
+func TestWriteClippings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteClippings(&buf, entries); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	expected := "\ufeff" + strings.Join([]string{
+		"On Call (example.com)",
+		"- Your Highlight on Location 3 | Added on Saturday, October 3, 2020 6:33:36 PM",
+		"",
+		`first, quoted "line"`,
+		"==========",
+		"On Call (example.com)",
+		"- Your Note on Location 3 | Added on Saturday, October 3, 2020 6:33:36 PM",
+		"",
+		"my note",
+		"==========",
+		"https://example.com/b (example.com)",
+		"- Your Highlight on Location 0 | Added on Saturday, October 3, 2020 6:33:36 PM",
+		"",
+		"second",
+		"==========",
+		"",
+	}, "\r\n")
+	if buf.String() != expected {
+		t.Errorf("expected\n%q\ngot\n%q", expected, buf.String())
+	}
+}
+
+func TestCollect(t *testing.T) {
+	var folders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/folders/list":
+			fmt.Fprint(w, `[{"folder_id":100,"title":"Reading"}]`)
+		case "/bookmarks/list":
+			folders = append(folders, r.FormValue("folder_id"))
+			if r.FormValue("folder_id") != "100" {
+				fmt.Fprint(w, `{"bookmarks":[]}`)
+				return
+			}
+			fmt.Fprint(w, `{"bookmarks":[{"bookmark_id":1,"title":"One"},{"bookmark_id":2,"title":"Two"}],
+				"highlights":[{"highlight_id":3,"bookmark_id":2,"position":0},
+					{"highlight_id":2,"bookmark_id":1,"position":5},{"highlight_id":1,"bookmark_id":1,"position":1}]}`)
+		default:
+			t.Errorf("unexpected call to %v", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client, _ := instapaper.NewClient("client_id", "client_secret", "username", "password", instapaper.WithBaseURL(server.URL))
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+
+	collected, err := Collect(context.Background(), client, "")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	var got []string
+	for _, e := range collected {
+		got = append(got, fmt.Sprintf("%d:%s", e.Highlight.ID, e.Bookmark.Title))
+	}
+	if strings.Join(got, " ") != "1:One 2:One 3:Two" {
+		t.Errorf("expected highlights grouped by bookmark in position order, got %v", got)
+	}
+	if strings.Join(folders, " ") != "unread archive 100" {
+		t.Errorf("expected every folder but the starred one to be listed, got %v", folders)
+	}
+
+	folders = nil
+	if _, err := Collect(context.Background(), client, "100"); err != nil || strings.Join(folders, " ") != "100" {
+		t.Errorf("expected only the given folder to be listed, got %v %v", folders, err)
+	}
+}
@@ -0,0 +1,233 @@
+// Package library exports every bookmark of an account as CSV or JSON Lines, one row per bookmark, for analysis in
+// spreadsheets, databases or data frames.
+//
+// Rows are written page by page as the bookmarks come in, so the memory used doesn't grow with the size of the library.
+package library
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// Format is the output format of an export
+type Format string
+
+const (
+	// CSV writes a header row followed by a row per bookmark
+	CSV Format = "csv"
+	// JSONL writes a JSON object per line, keys in the order of the columns
+	JSONL Format = "jsonl"
+)
+
+// Record is a bookmark along with what the export knows about it
+type Record struct {
+	Bookmark instapaper.Bookmark
+	// Folder is the folder the bookmark was listed in
+	Folder     instapaper.Folder
+	Highlights int
+}
+
+// columns maps every column name to its value, times are RFC 3339 strings - empty if unknown
+var columns = map[string]func(Record) interface{}{
+	"id":                 func(r Record) interface{} { return r.Bookmark.ID },
+	"url":                func(r Record) interface{} { return r.Bookmark.URL },
+	"title":              func(r Record) interface{} { return r.Bookmark.Title },
+	"description":        func(r Record) interface{} { return r.Bookmark.Description },
+	"hash":               func(r Record) interface{} { return r.Bookmark.Hash },
+	"private_source":     func(r Record) interface{} { return r.Bookmark.PrivateSource },
+	"folder":             func(r Record) interface{} { return r.Folder.Title },
+	"folder_id":          func(r Record) interface{} { return r.Folder.ID.String() },
+	"starred":            func(r Record) interface{} { return r.Bookmark.Starred == "1" },
+	"progress":           func(r Record) interface{} { return r.Bookmark.Progress },
+	"progress_timestamp": func(r Record) interface{} { return formatTime(r.Bookmark.ProgressTimestamp) },
+	"time":               func(r Record) interface{} { return formatTime(int64(r.Bookmark.Time)) },
+	"highlights":         func(r Record) interface{} { return r.Highlights },
+}
+
+// DefaultColumns are written when no columns are configured
+var DefaultColumns = []string{"id", "url", "title", "folder", "starred", "progress", "progress_timestamp", "time", "highlights"}
+
+// AllColumns lists every column an export can have
+var AllColumns = []string{"id", "url", "title", "description", "hash", "private_source", "folder", "folder_id", "starred",
+	"progress", "progress_timestamp", "time", "highlights"}
+
+func formatTime(unix int64) string {
+	if unix <= 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
+
+// Writer writes records one by one
+type Writer struct {
+	format  Format
+	columns []string
+	buf     *bufio.Writer
+	csv     *csv.Writer
+	header  bool
+}
+
+// NewWriter returns a Writer of the given format and columns, DefaultColumns if none are given
+func NewWriter(w io.Writer, format Format, columnNames ...string) (*Writer, error) {
+	if len(columnNames) == 0 {
+		columnNames = DefaultColumns
+	}
+	for _, name := range columnNames {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("library: unknown column %q", name)
+		}
+	}
+	writer := &Writer{
+		format:  format,
+		columns: columnNames,
+		buf:     bufio.NewWriter(w),
+	}
+	switch format {
+	case CSV:
+		writer.csv = csv.NewWriter(writer.buf)
+	case JSONL:
+	default:
+		return nil, fmt.Errorf("library: unknown format %q", format)
+	}
+	return writer, nil
+}
+
+// Write writes a record. The CSV header is written along with the first record
+func (w *Writer) Write(r Record) error {
+	if w.format == JSONL {
+		return w.writeJSON(r)
+	}
+	if !w.header {
+		w.header = true
+		if err := w.csv.Write(w.columns); err != nil {
+			return err
+		}
+	}
+	row := make([]string, len(w.columns))
+	for i, name := range w.columns {
+		switch v := columns[name](r).(type) {
+		case string:
+			row[i] = v
+		case int:
+			row[i] = strconv.Itoa(v)
+		case bool:
+			row[i] = strconv.FormatBool(v)
+		case float32:
+			row[i] = strconv.FormatFloat(float64(v), 'f', -1, 32)
+		}
+	}
+	return w.csv.Write(row)
+}
+
+func (w *Writer) writeJSON(r Record) error {
+	w.buf.WriteByte('{')
+	for i, name := range w.columns {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		value, err := json.Marshal(columns[name](r))
+		if err != nil {
+			return err
+		}
+		w.buf.Write(key)
+		w.buf.WriteByte(':')
+		w.buf.Write(value)
+	}
+	_, err := w.buf.WriteString("}\n")
+	return err
+}
+
+// Flush writes any buffered data, and the CSV header if no record was written
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		if !w.header {
+			w.header = true
+			w.csv.Write(w.columns)
+		}
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.buf.Flush()
+}
+
+// Export writes every bookmark of the account and returns how many were written. Starred bookmarks are written once,
+// with the folder they live in - the starred folder itself is skipped, see the starred column
+func Export(ctx context.Context, client *instapaper.Client, w *Writer) (int, error) {
+	folders, err := client.Folders.ListAllContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	written := 0
+	for _, folder := range folders {
+		if folder.ID.String() == instapaper.FolderIDStarred {
+			continue
+		}
+		n, err := exportFolder(ctx, client, folder, w)
+		written += n
+		if err != nil {
+			w.Flush()
+			return written, err
+		}
+		if err := w.Flush(); err != nil {
+			return written, err
+		}
+	}
+	return written, w.Flush()
+}
+
+// exportFolder lists the folder page by page rather than through BookmarkIterator, which keeps every highlight of the
+// folder. Only the IDs of the bookmarks seen so far are kept, the API needs them to send the next page
+func exportFolder(ctx context.Context, client *instapaper.Client, folder instapaper.Folder, w *Writer) (int, error) {
+	pageSize := instapaper.DefaultBookmarkListRequestParams.Limit
+	var have []instapaper.Bookmark
+	seen := map[int]bool{}
+	written := 0
+	for {
+		res, err := client.Bookmarks.ListContext(ctx, instapaper.BookmarkListRequestParams{
+			Limit:  pageSize,
+			Skip:   have,
+			Folder: folder.ID.String(),
+		})
+		if err != nil {
+			return written, err
+		}
+		// highlights come with the page of their bookmark
+		highlightCounts := map[int]int{}
+		for _, highlight := range res.Highlights {
+			highlightCounts[highlight.BookmarkID]++
+		}
+		fresh := 0
+		for _, bookmark := range res.Bookmarks {
+			if seen[bookmark.ID] {
+				continue
+			}
+			seen[bookmark.ID] = true
+			have = append(have, instapaper.Bookmark{ID: bookmark.ID})
+			fresh++
+			err := w.Write(Record{
+				Bookmark:   bookmark,
+				Folder:     folder,
+				Highlights: highlightCounts[bookmark.ID],
+			})
+			if err != nil {
+				return written, err
+			}
+			written++
+		}
+		// a page of nothing but duplicates means the API ignored "have", stop instead of looping forever
+		if len(res.Bookmarks) < pageSize || fresh == 0 {
+			return written, nil
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package library
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gomodule/oauth1/oauth"
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+func TestWriter(t *testing.T) {
+	record := Record{
+		Bookmark: instapaper.Bookmark{
+			ID:                1,
+			URL:               "https://example.com/a",
+			Title:             `Commas, and "quotes"`,
+			Starred:           "1",
+			Progress:          0.25,
+			ProgressTimestamp: 1601750016,
+		},
+		Folder:     instapaper.Folder{ID: "100", Title: "Reading"},
+		Highlights: 2,
+	}
+	cases := []struct {
+		format   Format
+		columns  []string
+		expected string
+	}{
+		{CSV, nil, "id,url,title,folder,starred,progress,progress_timestamp,time,highlights\n" +
+			"1,https://example.com/a,\"Commas, and \"\"quotes\"\"\",Reading,true,0.25,2020-10-03T18:33:36Z,,2\n"},
+		{CSV, []string{"folder_id", "id"}, "folder_id,id\n100,1\n"},
+		{JSONL, []string{"title", "starred", "progress", "time", "highlights"},
+			`{"title":"Commas, and \"quotes\"","starred":true,"progress":0.25,"time":"","highlights":2}` + "\n"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, c.format, c.columns...)
+		if err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		if err := w.Write(record); err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+		w.Flush()
+		if buf.String() != c.expected {
+			t.Errorf("expected\n%v\ngot\n%v", c.expected, buf.String())
+		}
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, CSV, "id", "tags"); err == nil {
+		t.Errorf("expected an error for an unknown column")
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, Format("xml")); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestExport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/folders/list":
+			fmt.Fprint(w, `[{"folder_id":100,"title":"Reading"}]`)
+		case "/bookmarks/list":
+			switch r.FormValue("folder_id") {
+			case "100":
+				fmt.Fprint(w, `{"bookmarks":[{"bookmark_id":1},{"bookmark_id":2,"starred":"1"}],
+					"highlights":[{"highlight_id":1,"bookmark_id":2},{"highlight_id":2,"bookmark_id":2}]}`)
+			case instapaper.FolderIDArchive:
+				fmt.Fprint(w, `{"bookmarks":[{"bookmark_id":3}],"highlights":[{"highlight_id":3,"bookmark_id":3}]}`)
+			case instapaper.FolderIDStarred:
+				t.Errorf("expected the starred folder to be skipped")
+			default:
+				fmt.Fprint(w, `{"bookmarks":[]}`)
+			}
+		default:
+			t.Errorf("unexpected call to %v", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client, _ := instapaper.NewClient("client_id", "client_secret", "username", "password", instapaper.WithBaseURL(server.URL))
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, CSV, "id", "folder", "starred", "highlights")
+	n, err := Export(context.Background(), client, w)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	expected := "id,folder,starred,highlights\n3,Archive,false,1\n1,Reading,false,0\n2,Reading,true,2\n"
+	if n != 3 || buf.String() != expected {
+		t.Errorf("expected 3 rows\n%v\ngot %d\n%v", expected, n, buf.String())
+	}
+}
+
+func TestExportPages(t *testing.T) {
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/folders/list":
+			fmt.Fprint(w, `[]`)
+		case r.FormValue("folder_id") != instapaper.FolderIDUnread:
+			fmt.Fprint(w, `{"bookmarks":[]}`)
+		case r.FormValue("have") == "":
+			// a full page, the highlight of its last bookmark along with it
+			pages++
+			var bookmarks []string
+			for id := 1; id <= 500; id++ {
+				bookmarks = append(bookmarks, fmt.Sprintf(`{"bookmark_id":%d}`, id))
+			}
+			fmt.Fprintf(w, `{"bookmarks":[%s],"highlights":[{"highlight_id":1,"bookmark_id":500}]}`, strings.Join(bookmarks, ","))
+		default:
+			pages++
+			if n := len(strings.Split(r.FormValue("have"), ",")); n != 500 {
+				t.Errorf("expected the first page to be sent as have, got %d bookmarks", n)
+			}
+			fmt.Fprint(w, `{"bookmarks":[{"bookmark_id":500},{"bookmark_id":501}],"highlights":[{"highlight_id":2,"bookmark_id":501}]}`)
+		}
+	}))
+	defer server.Close()
+	client, _ := instapaper.NewClient("client_id", "client_secret", "username", "password", instapaper.WithBaseURL(server.URL))
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, CSV, "id", "highlights")
+	n, err := Export(context.Background(), client, w)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if n != 501 || pages != 2 {
+		t.Errorf("expected 501 rows from 2 pages, got %d rows from %d pages", n, pages)
+	}
+	if !strings.Contains(buf.String(), "\n500,1\n501,1\n") {
+		t.Errorf("expected the highlights to be counted page by page, got the tail %q", buf.String()[buf.Len()-30:])
+	}
+}
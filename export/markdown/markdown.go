@@ -0,0 +1,246 @@
+// Package markdown exports bookmarks and their highlights as Markdown notes with YAML front matter, ready to be dropped
+// into an Obsidian vault or any other Markdown based knowledge base.
+//
+// Every bookmark gets its own file named after its ID and title. The generated part of a note ends with Marker,
+// anything written below it is preserved when the note is exported again. A note whose Marker was removed is never
+// overwritten.
+package markdown
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// Marker separates the generated part of a note from the user's own content below it
+const Marker = "<!-- instapaper-export: anything below this line is kept on re-export -->"
+
+// maxSlugLength keeps file names well below file system limits
+const maxSlugLength = 60
+
+// Note is everything that goes into a single exported file
+type Note struct {
+	Bookmark instapaper.Bookmark
+	// Folder is the title of the folder the bookmark is in
+	Folder     string
+	Highlights []instapaper.Highlight
+}
+
+// Exporter writes notes into a directory. It lists the directory once, on the first Write, and keeps track of the
+// notes it writes from then on
+type Exporter struct {
+	Dir string
+	// paths maps bookmark IDs to the notes in Dir, read on the first Write
+	paths map[int]string
+}
+
+// Write creates or updates the note of the bookmark and returns its path.
+// An existing note keeps its file name even if the title changed, and everything below its Marker. An existing note
+// without a Marker is left alone and an error naming it is returned
+func (e *Exporter) Write(note Note) (string, error) {
+	if err := os.MkdirAll(e.Dir, 0755); err != nil {
+		return "", err
+	}
+	if e.paths == nil {
+		if err := e.readPaths(); err != nil {
+			return "", err
+		}
+	}
+	path, ok := e.paths[note.Bookmark.ID]
+	var userContent []byte
+	if !ok {
+		path = filepath.Join(e.Dir, FileName(note.Bookmark))
+	} else {
+		existing, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		i := bytes.Index(existing, []byte(Marker))
+		if i < 0 {
+			// there's no telling the generated part from the user's own content
+			return path, fmt.Errorf("markdown: %s has no export marker, not overwriting it", path)
+		}
+		userContent = bytes.TrimPrefix(existing[i+len(Marker):], []byte("\n"))
+	}
+	content := append(Render(note), userContent...)
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return path, err
+	}
+	e.paths[note.Bookmark.ID] = path
+	return path, nil
+}
+
+// readPaths indexes the notes already in Dir by bookmark ID
+func (e *Exporter) readPaths() error {
+	entries, err := ioutil.ReadDir(e.Dir)
+	if err != nil {
+		return err
+	}
+	e.paths = map[int]string{}
+	// entries are sorted by name, the first note of a bookmark wins
+	for _, entry := range entries {
+		name := entry.Name()
+		i := strings.Index(name, "-")
+		if entry.IsDir() || i < 0 || !strings.HasSuffix(name, ".md") {
+			continue
+		}
+		id, err := strconv.Atoi(name[:i])
+		if err != nil {
+			continue
+		}
+		if _, ok := e.paths[id]; !ok {
+			e.paths[id] = filepath.Join(e.Dir, name)
+		}
+	}
+	return nil
+}
+
+// FileName returns the file name of the bookmark's note: its ID followed by a slug of its title
+func FileName(bookmark instapaper.Bookmark) string {
+	slug := slugify(bookmark.Title)
+	if slug == "" {
+		slug = "untitled"
+	}
+	return fmt.Sprintf("%d-%s.md", bookmark.ID, slug)
+}
+
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := []rune(strings.TrimRight(b.String(), "-"))
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+	return strings.TrimRight(string(slug), "-")
+}
+
+// Render returns the generated part of the note, ending with the Marker line
+func Render(note Note) []byte {
+	b := note.Bookmark
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	fmt.Fprintf(&buf, "id: %d\n", b.ID)
+	fmt.Fprintf(&buf, "url: %s\n", yamlString(b.URL))
+	fmt.Fprintf(&buf, "title: %s\n", yamlString(b.Title))
+	fmt.Fprintf(&buf, "folder: %s\n", yamlString(note.Folder))
+	fmt.Fprintf(&buf, "starred: %t\n", b.Starred == "1")
+	fmt.Fprintf(&buf, "progress: %s\n", strconv.FormatFloat(float64(b.Progress), 'f', -1, 32))
+	fmt.Fprintf(&buf, "saved: %s\n", time.Unix(int64(b.Time), 0).UTC().Format(time.RFC3339))
+	buf.WriteString("source: instapaper\n")
+	buf.WriteString("---\n\n")
+
+	title := b.Title
+	if title == "" {
+		title = b.URL
+	}
+	fmt.Fprintf(&buf, "# %s\n\n", title)
+	fmt.Fprintf(&buf, "<%s>\n\n", b.URL)
+	if description := strings.TrimSpace(b.Description); description != "" {
+		buf.WriteString(description + "\n\n")
+	}
+
+	highlights := append([]instapaper.Highlight(nil), note.Highlights...)
+	sort.SliceStable(highlights, func(i, j int) bool {
+		return highlights[i].Position < highlights[j].Position
+	})
+	if len(highlights) > 0 {
+		buf.WriteString("## Highlights\n\n")
+		for _, highlight := range highlights {
+			buf.WriteString(blockquote(highlight.Text))
+			if note := strings.TrimSpace(highlight.Note); note != "" {
+				buf.WriteString("\n" + note + "\n")
+			}
+			buf.WriteString("\n")
+		}
+	}
+	buf.WriteString(Marker + "\n")
+	return buf.Bytes()
+}
+
+func blockquote(text string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			b.WriteString(">\n")
+			continue
+		}
+		b.WriteString("> " + line + "\n")
+	}
+	return b.String()
+}
+
+// yamlString quotes s as a YAML double-quoted scalar - JSON string syntax is a subset of it
+func yamlString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// ExportFolder writes a note for every bookmark in the folder, fetching the highlights of each one
+func (e *Exporter) ExportFolder(ctx context.Context, client *instapaper.Client, folder instapaper.Folder) ([]string, error) {
+	var paths []string
+	it := client.Bookmarks.ListAll(ctx, folder.ID.String())
+	for it.Next() {
+		bookmark := it.Bookmark()
+		highlights, err := client.Highlights.ListContext(ctx, bookmark.ID)
+		if err != nil {
+			return paths, err
+		}
+		path, err := e.Write(Note{
+			Bookmark:   bookmark,
+			Folder:     folder.Title,
+			Highlights: highlights,
+		})
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, it.Err()
+}
+
+// ExportAll writes a note for every bookmark of the account. Starred bookmarks are exported from the folder they're in,
+// the starred folder itself is skipped - the front matter tells whether a bookmark is starred
+func (e *Exporter) ExportAll(ctx context.Context, client *instapaper.Client) ([]string, error) {
+	folders, err := client.Folders.ListAllContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, folder := range folders {
+		if folder.ID.String() == instapaper.FolderIDStarred {
+			continue
+		}
+		folderPaths, err := e.ExportFolder(ctx, client, folder)
+		paths = append(paths, folderPaths...)
+		if err != nil {
+			return paths, err
+		}
+	}
+	return paths, nil
+}
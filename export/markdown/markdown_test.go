@@ -0,0 +1,184 @@
+package markdown
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gomodule/oauth1/oauth"
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "instapaper-markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestFileName(t *testing.T) {
+	cases := map[string]string{
+		"On Call Shouldn’t Suck: A Guide For Managers": "1-on-call-shouldn-t-suck-a-guide-for-managers.md",
+		"":           "1-untitled.md",
+		"???":        "1-untitled.md",
+		"Ünïcödé ok": "1-ünïcödé-ok.md",
+	}
+	for title, expected := range cases {
+		if name := FileName(instapaper.Bookmark{ID: 1, Title: title}); name != expected {
+			t.Errorf("expected %v, got %v", expected, name)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	note := Note{
+		Bookmark: instapaper.Bookmark{
+			ID:       1,
+			Title:    `Quotes "inside"`,
+			URL:      "https://example.com/a",
+			Time:     1601750016, // exactly representable as a float32
+			Progress: 0.25,
+			Starred:  "1",
+		},
+		Folder: "Reading",
+		Highlights: []instapaper.Highlight{
+			{ID: 2, Text: "second", Position: 2},
+			{ID: 1, Text: "first line\nsecond line", Note: "my note", Position: 1},
+		},
+	}
+	expected := `---
+id: 1
+url: "https://example.com/a"
+title: "Quotes \"inside\""
+folder: "Reading"
+starred: true
+progress: 0.25
+saved: 2020-10-03T18:33:36Z
+source: instapaper
+---
+
+# Quotes "inside"
+
+<https://example.com/a>
+
+## Highlights
+
+> first line
+> second line
+
+my note
+
+> second
+
+` + Marker + "\n"
+	if rendered := string(Render(note)); rendered != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, rendered)
+	}
+}
+
+func TestReExportKeepsUserContent(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	e := &Exporter{Dir: dir}
+	note := Note{Bookmark: instapaper.Bookmark{ID: 7, Title: "Original"}}
+	path, err := e.Write(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadFile(path)
+	content = append(content, []byte("\nMy own thoughts.\n")...)
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	note.Bookmark.Title = "Renamed"
+	newPath, err := e.Write(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newPath != path {
+		t.Errorf("expected the file name to stay %v, got %v", path, newPath)
+	}
+	content, _ = ioutil.ReadFile(path)
+	if !strings.Contains(string(content), "# Renamed") || !strings.HasSuffix(string(content), Marker+"\n\nMy own thoughts.\n") {
+		t.Errorf("expected the generated part to be updated and the user content kept, got\n%s", content)
+	}
+}
+
+func TestReExportWithoutMarker(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	// glob metacharacters in the directory must not hide the existing note
+	dir = filepath.Join(dir, "notes [*]")
+	e := &Exporter{Dir: dir}
+	note := Note{Bookmark: instapaper.Bookmark{ID: 7, Title: "Original"}}
+	path, err := e.Write(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadFile(path)
+	edited := strings.Replace(string(content), Marker, "", 1) + "My own thoughts.\n"
+	if err := ioutil.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	note.Bookmark.Title = "Renamed"
+	if _, err := e.Write(note); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("expected an error naming %v, got %v", path, err)
+	}
+	content, _ = ioutil.ReadFile(path)
+	if string(content) != edited {
+		t.Errorf("expected the note to be left alone, got\n%s", content)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("expected no duplicate note, got %d files", len(files))
+	}
+}
+
+func TestExportAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/folders/list":
+			fmt.Fprint(w, `[{"folder_id":100,"title":"Reading"}]`)
+		case "/bookmarks/list":
+			switch r.FormValue("folder_id") {
+			case "100":
+				fmt.Fprint(w, `{"bookmarks":[{"bookmark_id":1,"title":"In a folder"}]}`)
+			case instapaper.FolderIDStarred:
+				t.Errorf("expected the starred folder to be skipped")
+			default:
+				fmt.Fprint(w, `{"bookmarks":[]}`)
+			}
+		case "/bookmarks/1/highlights":
+			fmt.Fprint(w, `[{"highlight_id":1,"bookmark_id":1,"text":"quoted"}]`)
+		default:
+			t.Errorf("unexpected call to %v", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client, _ := instapaper.NewClient("client_id", "client_secret", "username", "password", instapaper.WithBaseURL(server.URL))
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	e := &Exporter{Dir: dir}
+	paths, err := e.ExportAll(context.Background(), client)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if len(paths) != 1 || filepath.Base(paths[0]) != "1-in-a-folder.md" {
+		t.Fatalf("expected a single note, got %v", paths)
+	}
+	content, _ := ioutil.ReadFile(paths[0])
+	if !strings.Contains(string(content), `folder: "Reading"`) || !strings.Contains(string(content), "> quoted") {
+		t.Errorf("unexpected note content\n%s", content)
+	}
+}
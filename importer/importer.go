@@ -0,0 +1,207 @@
+// Package importer moves bookmarks saved with other read-later services into Instapaper. It reads Pocket's HTML and CSV
+// exports, Pinboard's JSON export and Instapaper's own CSV export into Items, then adds them to an account.
+//
+// An import can be interrupted and run again: with a journal, the items already added are skipped.
+package importer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+	"github.com/ochronus/instapaper-go-client/internal/adder"
+)
+
+// Item is a bookmark read from an export, independent of the service it comes from
+type Item struct {
+	URL         string
+	Title       string
+	Description string
+	// Folder is the title of the folder the item goes to, empty for the unread folder
+	Folder   string
+	Tags     []string
+	Archived bool
+	Starred  bool
+	Added    time.Time
+}
+
+// Outcome is what happened to a single item
+type Outcome int
+
+const (
+	// Added means the item was saved to the account
+	Added Outcome = iota
+	// Skipped means the item was imported by an earlier run, or is a duplicate of an earlier item
+	Skipped
+	// Failed means Instapaper refused the item, see Report.Failures
+	Failed
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Added:
+		return "added"
+	case Skipped:
+		return "skipped"
+	}
+	return "failed"
+}
+
+// Progress is reported after every item
+type Progress struct {
+	Done    int
+	Total   int
+	Item    Item
+	Outcome Outcome
+}
+
+// Failure is an item Instapaper refused, and why
+type Failure struct {
+	Item Item
+	Err  error
+}
+
+// Report sums up an import
+type Report struct {
+	Added    int
+	Skipped  int
+	Failures []Failure
+}
+
+// Importer adds items to an Instapaper account. Items are added oldest first so the account keeps their original order,
+// archived and starred ones are archived or starred right after being added
+type Importer struct {
+	Client *instapaper.Client
+	// Journal is the path of the file recording the URLs already imported. It's appended to after every item, so an
+	// interrupted import picks up where it stopped. No journal is kept if empty
+	Journal string
+	// TagFolders files every item without a folder under a folder named after its first tag - Instapaper has no tags
+	TagFolders bool
+	// Progress is called after every item, if set
+	Progress func(Progress)
+}
+
+// Import adds the items to the account. Items Instapaper refuses - an invalid URL, a domain opted out of Instapaper -
+// are reported as failures and the import goes on, any other error stops it
+func (im *Importer) Import(ctx context.Context, items []Item) (*Report, error) {
+	report := &Report{}
+	done, err := readJournal(im.Journal)
+	if err != nil {
+		return report, err
+	}
+	journal, err := im.openJournal()
+	if err != nil {
+		return report, err
+	}
+	if journal != nil {
+		defer journal.Close()
+	}
+
+	add, err := adder.New(ctx, im.Client.Bookmarks, im.Client.Folders, false)
+	if err != nil {
+		return report, err
+	}
+
+	items = append([]Item(nil), items...)
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Added.Before(items[j].Added)
+	})
+	for i, item := range items {
+		outcome := Skipped
+		if !done[item.URL] {
+			err := im.importItem(ctx, item, add)
+			switch {
+			case err == nil:
+				outcome = Added
+				report.Added++
+				done[item.URL] = true
+				if journal != nil {
+					if _, err := journal.WriteString(item.URL + "\n"); err != nil {
+						return report, err
+					}
+				}
+			case refused(err):
+				outcome = Failed
+				report.Failures = append(report.Failures, Failure{Item: item, Err: err})
+			default:
+				return report, err
+			}
+		}
+		if outcome == Skipped {
+			report.Skipped++
+		}
+		if im.Progress != nil {
+			im.Progress(Progress{Done: i + 1, Total: len(items), Item: item, Outcome: outcome})
+		}
+	}
+	return report, nil
+}
+
+func (im *Importer) importItem(ctx context.Context, item Item, add *adder.Adder) error {
+	folder := item.Folder
+	if folder == "" && im.TagFolders && len(item.Tags) > 0 {
+		folder = item.Tags[0]
+	}
+	folderID := ""
+	if folder != "" {
+		var err error
+		if folderID, _, err = add.FolderID(ctx, folder); err != nil {
+			return err
+		}
+	}
+	params := instapaper.BookmarkAddRequestParams{
+		URL:         item.URL,
+		Title:       item.Title,
+		Description: item.Description,
+		Folder:      folderID,
+	}
+	return add.Add(ctx, params, item.Starred, item.Archived)
+}
+
+// refused tells whether Instapaper rejected the bookmark itself, as opposed to the request failing
+func refused(err error) bool {
+	var apiErr *instapaper.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode {
+	case instapaper.ErrFullContentRequired, instapaper.ErrDomainNotSupported, instapaper.ErrInvalidURL,
+		instapaper.ErrSuppliedContentRequired, instapaper.ErrUnexpected, instapaper.ErrCannotAddBookmarkToFolder:
+		return true
+	}
+	return false
+}
+
+func readJournal(path string) (map[string]bool, error) {
+	done := map[string]bool{}
+	if path == "" {
+		return done, nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			done[line] = true
+		}
+	}
+	return done, scanner.Err()
+}
+
+func (im *Importer) openJournal() (*os.File, error) {
+	if im.Journal == "" {
+		return nil, nil
+	}
+	return os.OpenFile(im.Journal, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+}
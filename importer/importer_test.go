@@ -0,0 +1,245 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomodule/oauth1/oauth"
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+func TestParsePocketHTML(t *testing.T) {
+	export := `<!DOCTYPE html>
+<html><head><title>Pocket Export</title></head><body>
+<h1>Unread</h1>
+<ul>
+<li><a href="https://example.com/a" time_added="1601750016" tags="go,reading">A &amp; B</a></li>
+</ul>
+
+<h1>Read Archive</h1>
+<ul>
+<li><a href="https://example.com/b" time_added="1601750000" tags="">https://example.com/b</a></li>
+</ul>
+</body></html>`
+	items, err := ParsePocketHTML(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	expected := []Item{
+		{URL: "https://example.com/a", Title: "A & B", Tags: []string{"go", "reading"}, Added: time.Unix(1601750016, 0)},
+		{URL: "https://example.com/b", Title: "https://example.com/b", Added: time.Unix(1601750000, 0), Archived: true},
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("expected %+v, got %+v", expected, items)
+	}
+}
+
+func TestParsePocketHTMLMalformed(t *testing.T) {
+	export := `<h1>Unread</h1>stray text</a>
+<ul><li><a href="https://example.com/a">A</a></a></li></ul>`
+	items, err := ParsePocketHTML(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	expected := []Item{{URL: "https://example.com/a", Title: "A"}}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("expected %+v, got %+v", expected, items)
+	}
+}
+
+func TestParsePocketCSV(t *testing.T) {
+	export := "title,url,time_added,tags,status\n" +
+		"\"Quoted, title\",https://example.com/a,1601750016,go|reading,unread\n" +
+		"https://example.com/b,https://example.com/b,1601750000,,archive\n"
+	items, err := ParsePocketCSV(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	expected := []Item{
+		{URL: "https://example.com/a", Title: "Quoted, title", Tags: []string{"go", "reading"}, Added: time.Unix(1601750016, 0)},
+		{URL: "https://example.com/b", Added: time.Unix(1601750000, 0), Archived: true},
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("expected %+v, got %+v", expected, items)
+	}
+}
+
+func TestParsePinboardJSON(t *testing.T) {
+	export := `[
+		{"href":"https://example.com/a","description":"A","extended":"notes","meta":"x","hash":"y","time":"2020-10-03T18:33:36Z","shared":"no","toread":"yes","tags":"go reading"},
+		{"href":"https://example.com/b","description":"B","extended":"","time":"2020-10-03T18:33:20Z","shared":"yes","toread":"no","tags":""}
+	]`
+	items, err := ParsePinboardJSON(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	expected := []Item{
+		{URL: "https://example.com/a", Title: "A", Description: "notes", Tags: []string{"go", "reading"}, Added: time.Date(2020, 10, 3, 18, 33, 36, 0, time.UTC)},
+		{URL: "https://example.com/b", Title: "B", Tags: []string{}, Added: time.Date(2020, 10, 3, 18, 33, 20, 0, time.UTC), Archived: true},
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("expected %+v, got %+v", expected, items)
+	}
+}
+
+func TestParseInstapaperCSV(t *testing.T) {
+	export := "\ufeffURL,Title,Selection,Folder,Timestamp,Tags\n" +
+		"https://example.com/a,A,,Unread,1601750016,[]\n" +
+		"https://example.com/b,B,a quote,Archive,1601750000,\"[\"\"go\"\", \"\"reading\"\"]\"\n" +
+		"https://example.com/c,C,,Starred,1601750000,\n" +
+		"https://example.com/d,D,,Tech,1601750000,\n"
+	items, err := ParseInstapaperCSV(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	unix := time.Unix(1601750000, 0)
+	expected := []Item{
+		{URL: "https://example.com/a", Title: "A", Added: time.Unix(1601750016, 0)},
+		{URL: "https://example.com/b", Title: "B", Description: "a quote", Tags: []string{"go", "reading"}, Added: unix, Archived: true},
+		{URL: "https://example.com/c", Title: "C", Added: unix, Starred: true},
+		{URL: "https://example.com/d", Title: "D", Folder: "Tech", Added: unix},
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("expected %+v, got %+v", expected, items)
+	}
+}
+
+func TestImportResumes(t *testing.T) {
+	var calls []string
+	failOn := "https://example.com/c"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/folders/list":
+			fmt.Fprint(w, `[{"folder_id":100,"title":"Tech"}]`)
+		case "/folders/add":
+			calls = append(calls, "folders/add "+r.FormValue("title"))
+			fmt.Fprint(w, `[{"type":"folder","folder_id":200,"title":"`+r.FormValue("title")+`"}]`)
+		case "/bookmarks/add":
+			switch r.FormValue("url") {
+			case "https://example.com/bad":
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `[{"type":"error","error_code":1240,"message":"Invalid URL specified"}]`)
+				return
+			case failOn:
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `[{"type":"error","error_code":1042,"message":"Application is suspended"}]`)
+				return
+			}
+			calls = append(calls, fmt.Sprintf("bookmarks/add %v %v", r.FormValue("url"), r.FormValue("folder_id")))
+			fmt.Fprint(w, `[{"type":"bookmark","bookmark_id":300}]`)
+		case "/bookmarks/star", "/bookmarks/archive":
+			calls = append(calls, r.URL.Path[1:]+" "+r.FormValue("bookmark_id"))
+			fmt.Fprint(w, `[{"type":"bookmark","bookmark_id":300}]`)
+		default:
+			t.Errorf("unexpected call to %v", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client, _ := instapaper.NewClient("client_id", "client_secret", "username", "password", instapaper.WithBaseURL(server.URL))
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+
+	dir, err := ioutil.TempDir("", "instapaper-importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	items := []Item{
+		{URL: "https://example.com/c", Added: time.Unix(3, 0)},
+		{URL: "https://example.com/a", Folder: "tech", Added: time.Unix(1, 0)},
+		{URL: "https://example.com/b", Tags: []string{"go"}, Starred: true, Archived: true, Added: time.Unix(2, 0)},
+		{URL: "https://example.com/bad", Added: time.Unix(2, 0)},
+		{URL: "https://example.com/a", Added: time.Unix(4, 0)},
+	}
+	var outcomes []string
+	im := &Importer{
+		Client:     client,
+		Journal:    filepath.Join(dir, "journal"),
+		TagFolders: true,
+		Progress: func(p Progress) {
+			outcomes = append(outcomes, fmt.Sprintf("%d/%d %v %v", p.Done, p.Total, p.Item.URL, p.Outcome))
+		},
+	}
+	report, err := im.Import(context.Background(), items)
+	if apiErr, ok := err.(*instapaper.APIError); !ok || apiErr.ErrorCode != instapaper.ErrApplicationSuspended {
+		t.Fatalf("expected the import to stop on error 1042, got %v", err)
+	}
+	if report.Added != 2 || len(report.Failures) != 1 || report.Failures[0].Item.URL != "https://example.com/bad" {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	failOn = ""
+	calls, outcomes = nil, nil
+	report, err = im.Import(context.Background(), items)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if report.Added != 1 || report.Skipped != 3 || len(report.Failures) != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if expected := []string{"bookmarks/add https://example.com/c "}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected only the remaining item to be added, got %v", calls)
+	}
+	expected := []string{
+		"1/5 https://example.com/a skipped",
+		"2/5 https://example.com/b skipped",
+		"3/5 https://example.com/bad failed",
+		"4/5 https://example.com/c added",
+		"5/5 https://example.com/a skipped",
+	}
+	if !reflect.DeepEqual(outcomes, expected) {
+		t.Errorf("expected progress\n%v\ngot\n%v", strings.Join(expected, "\n"), strings.Join(outcomes, "\n"))
+	}
+}
+
+func TestImportCreatesFolders(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/folders/list":
+			fmt.Fprint(w, `[]`)
+		case "/folders/add":
+			calls = append(calls, "folders/add "+r.FormValue("title"))
+			fmt.Fprint(w, `[{"type":"folder","folder_id":200,"title":"`+r.FormValue("title")+`"}]`)
+		case "/bookmarks/add":
+			calls = append(calls, fmt.Sprintf("bookmarks/add %v %v", r.FormValue("url"), r.FormValue("folder_id")))
+			fmt.Fprint(w, `[{"type":"bookmark","bookmark_id":300}]`)
+		case "/bookmarks/star", "/bookmarks/archive":
+			calls = append(calls, r.URL.Path[1:]+" "+r.FormValue("bookmark_id"))
+			fmt.Fprint(w, `[{"type":"bookmark","bookmark_id":300}]`)
+		default:
+			t.Errorf("unexpected call to %v", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client, _ := instapaper.NewClient("client_id", "client_secret", "username", "password", instapaper.WithBaseURL(server.URL))
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+
+	im := &Importer{Client: client, TagFolders: true}
+	_, err := im.Import(context.Background(), []Item{
+		{URL: "https://example.com/a", Tags: []string{"Go", "reading"}, Starred: true, Archived: true},
+		{URL: "https://example.com/b", Folder: "go"},
+	})
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	expected := []string{
+		"folders/add Go",
+		"bookmarks/add https://example.com/a 200",
+		"bookmarks/star 300",
+		"bookmarks/archive 300",
+		"bookmarks/add https://example.com/b 200",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls\n%v\ngot\n%v", strings.Join(expected, "\n"), strings.Join(calls, "\n"))
+	}
+}
@@ -0,0 +1,66 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// ParseInstapaperCSV reads the CSV export of instapaper.com, with URL, Title, Selection, Folder and Timestamp columns
+// and, in recent exports, Tags. The built-in folders are mapped onto the Archived and Starred flags, the selection
+// becomes the description
+func ParseInstapaperCSV(r io.Reader) ([]Item, error) {
+	rows, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	for _, row := range rows {
+		item := Item{
+			URL:         row.get("url"),
+			Title:       row.get("title"),
+			Description: row.get("selection"),
+			Tags:        parseInstapaperTags(row.get("tags")),
+			Added:       parseUnix(row.get("timestamp")),
+		}
+		folder := row.get("folder")
+		switch {
+		case isBuiltIn(folder, instapaper.FolderIDUnread), folder == "":
+		case isBuiltIn(folder, instapaper.FolderIDArchive):
+			item.Archived = true
+		case isBuiltIn(folder, instapaper.FolderIDStarred):
+			item.Starred = true
+		default:
+			item.Folder = folder
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// isBuiltIn tells whether title names the built-in folder with the given ID
+func isBuiltIn(title, folderID string) bool {
+	for _, folder := range instapaper.BuiltInFolders {
+		if folder.ID.String() == folderID {
+			return strings.EqualFold(title, folder.Title) || strings.EqualFold(title, folder.DisplayTitle)
+		}
+	}
+	return false
+}
+
+// parseInstapaperTags reads the Tags column, a JSON list of strings
+func parseInstapaperTags(s string) []string {
+	var tags []string
+	if err := json.Unmarshal([]byte(s), &tags); err != nil {
+		return splitTags(s, ",")
+	}
+	var cleaned []string
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	return cleaned
+}
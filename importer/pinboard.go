@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pinboardPost is a bookmark of Pinboard's JSON export, the same shape as the posts/all API response
+type pinboardPost struct {
+	Href        string
+	Description string
+	Extended    string
+	Time        time.Time
+	ToRead      string
+	Tags        string
+}
+
+// ParsePinboardJSON reads Pinboard's JSON export. Pinboard has no archive: posts marked "to read" go to the unread
+// folder, every other post is archived. Tags are separated by spaces
+func ParsePinboardJSON(r io.Reader) ([]Item, error) {
+	var posts []pinboardPost
+	if err := json.NewDecoder(r).Decode(&posts); err != nil {
+		return nil, err
+	}
+	items := make([]Item, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, Item{
+			URL:         strings.TrimSpace(post.Href),
+			Title:       post.Description,
+			Description: post.Extended,
+			Tags:        strings.Fields(post.Tags),
+			Added:       post.Time,
+			Archived:    post.ToRead != "yes",
+		})
+	}
+	return items, nil
+}
+
+// parseUnix reads a Unix timestamp in seconds, returning the zero time if it isn't one
+func parseUnix(s string) time.Time {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(n, 0)
+}
+
+func splitTags(s, sep string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, sep) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/ochronus/instapaper-go-client/netscape"
+)
+
+// ParsePocketHTML reads Pocket's classic ril_export.html: a list of links under an "Unread" heading and another one
+// under "Read Archive", with the save time and comma separated tags as attributes of the links. It's a bookmark file
+// look-alike, read by netscape.Parse
+func ParsePocketHTML(r io.Reader) ([]Item, error) {
+	root, err := netscape.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	err = root.Walk(func(path []string, b netscape.Bookmark) error {
+		// the first heading ends up as the title of the file, the following ones as folders
+		section := root.Title
+		if len(path) > 0 {
+			section = path[0]
+		}
+		items = append(items, Item{
+			URL:      b.URL,
+			Title:    b.Title,
+			Tags:     b.Tags,
+			Added:    b.AddDate,
+			Archived: strings.Contains(strings.ToLower(section), "archive"),
+		})
+		return nil
+	})
+	return items, err
+}
+
+// ParsePocketCSV reads the CSV export Pocket switched to in 2024, with title, url, time_added, tags and status columns.
+// Tags are separated by "|", the status is either "unread" or "archive"
+func ParsePocketCSV(r io.Reader) ([]Item, error) {
+	rows, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	for _, row := range rows {
+		title := row.get("title")
+		if title == row.get("url") {
+			// Pocket fills in the URL when it has no title
+			title = ""
+		}
+		items = append(items, Item{
+			URL:      row.get("url"),
+			Title:    title,
+			Tags:     splitTags(row.get("tags"), "|"),
+			Added:    parseUnix(row.get("time_added")),
+			Archived: strings.EqualFold(row.get("status"), "archive"),
+		})
+	}
+	return items, nil
+}
+
+// csvRow is a record of a CSV file with a header, looked up by column name
+type csvRow struct {
+	columns map[string]int
+	record  []string
+}
+
+func (r csvRow) get(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+// readCSV reads a CSV file whose first record names the columns, names are matched case insensitively
+func readCSV(r io.Reader) ([]csvRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	var rows []csvRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, csvRow{columns: columns, record: record})
+	}
+}
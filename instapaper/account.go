@@ -0,0 +1,68 @@
+package instapaper
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+)
+
+// User represents the Instapaper account the OAuth tokens belong to
+type User struct {
+	ID                   int    `json:"user_id"`
+	Username             string `json:"username"`
+	SubscriptionIsActive string `json:"subscription_is_active"`
+}
+
+// HasActiveSubscription tells whether the user has an active premium subscription
+func (u *User) HasActiveSubscription() bool {
+	return u.SubscriptionIsActive == "1"
+}
+
+// AccountService encapsulates the account related endpoints
+type AccountService struct {
+	Client *Client
+}
+
+// VerifyCredentials returns the user the client's credentials belong to. It fails if the credentials are no longer valid
+func (svc *AccountService) VerifyCredentials() (*User, error) {
+	return svc.VerifyCredentialsContext(context.Background())
+}
+
+// VerifyCredentialsContext is like VerifyCredentials but the request is bound to ctx
+func (svc *AccountService) VerifyCredentialsContext(ctx context.Context) (*User, error) {
+	return svc.Client.verifyCredentials(ctx)
+}
+
+func (svc *Client) verifyCredentials(ctx context.Context) (*User, error) {
+	res, err := svc.CallContext(ctx, "/account/verify_credentials", nil)
+	if err != nil {
+		return nil, err
+	}
+	bodyBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, &APIError{
+			StatusCode:   res.StatusCode,
+			Message:      err.Error(),
+			ErrorCode:    ErrHTTPError,
+			WrappedError: err,
+		}
+	}
+	var userList []User
+	err = json.Unmarshal(bodyBytes, &userList)
+	if err != nil {
+		return nil, &APIError{
+			StatusCode:   res.StatusCode,
+			Message:      err.Error(),
+			ErrorCode:    ErrUnmarshalError,
+			WrappedError: err,
+		}
+	}
+	if len(userList) == 0 {
+		return nil, &APIError{
+			StatusCode: res.StatusCode,
+			Message:    "no user in the verify_credentials response",
+			ErrorCode:  ErrUnmarshalError,
+		}
+	}
+	return &userList[0], nil
+}
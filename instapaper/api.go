@@ -0,0 +1,68 @@
+package instapaper
+
+import "context"
+
+// BookmarkAPI is every bookmark operation, implemented by BookmarkService. Depend on it instead of the concrete type to
+// swap in a mock or a cache - see the instapapermock package
+type BookmarkAPI interface {
+	List(p BookmarkListRequestParams) (*BookmarkListResponse, error)
+	ListContext(ctx context.Context, p BookmarkListRequestParams) (*BookmarkListResponse, error)
+	ListAll(ctx context.Context, folder string) *BookmarkIterator
+	GetText(bookmarkID int) (string, error)
+	GetTextContext(ctx context.Context, bookmarkID int) (string, error)
+	Star(bookmarkID int) error
+	StarContext(ctx context.Context, bookmarkID int) error
+	UnStar(bookmarkID int) error
+	UnStarContext(ctx context.Context, bookmarkID int) error
+	Archive(bookmarkID int) error
+	ArchiveContext(ctx context.Context, bookmarkID int) error
+	UnArchive(bookmarkID int) error
+	UnArchiveContext(ctx context.Context, bookmarkID int) error
+	DeletePermanently(bookmarkID int) error
+	DeletePermanentlyContext(ctx context.Context, bookmarkID int) error
+	Move(bookmarkID int, folderID string) error
+	MoveContext(ctx context.Context, bookmarkID int, folderID string) error
+	UpdateReadProgress(bookmarkID int, progress float32, when int64) error
+	UpdateReadProgressContext(ctx context.Context, bookmarkID int, progress float32, when int64) error
+	Add(p BookmarkAddRequestParams) (*Bookmark, error)
+	AddContext(ctx context.Context, p BookmarkAddRequestParams) (*Bookmark, error)
+}
+
+// FolderAPI is every folder operation, implemented by FolderService
+type FolderAPI interface {
+	List() ([]Folder, error)
+	ListContext(ctx context.Context) ([]Folder, error)
+	ListAll() ([]Folder, error)
+	ListAllContext(ctx context.Context) ([]Folder, error)
+	Add(title string) (*Folder, error)
+	AddContext(ctx context.Context, title string) (*Folder, error)
+	Delete(folderID string) error
+	DeleteContext(ctx context.Context, folderID string) error
+	SetOrder(folderOrderlist string) ([]Folder, error)
+	SetOrderContext(ctx context.Context, folderOrderlist string) ([]Folder, error)
+}
+
+// HighlightAPI is every highlight operation, implemented by HighlightService
+type HighlightAPI interface {
+	List(bookmarkID int) ([]Highlight, error)
+	ListContext(ctx context.Context, bookmarkID int) ([]Highlight, error)
+	Add(bookmarkID int, text string, position int) (*Highlight, error)
+	AddContext(ctx context.Context, bookmarkID int, text string, position int) (*Highlight, error)
+	Delete(highlightID int) error
+	DeleteContext(ctx context.Context, highlightID int) error
+}
+
+// AccountAPI is every account operation, implemented by AccountService
+type AccountAPI interface {
+	VerifyCredentials() (*User, error)
+	VerifyCredentialsContext(ctx context.Context) (*User, error)
+	Snapshot() (*Account, error)
+	SnapshotContext(ctx context.Context) (*Account, error)
+}
+
+var (
+	_ BookmarkAPI  = (*BookmarkService)(nil)
+	_ FolderAPI    = (*FolderService)(nil)
+	_ HighlightAPI = (*HighlightService)(nil)
+	_ AccountAPI   = (*AccountService)(nil)
+)
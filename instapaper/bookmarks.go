@@ -1,6 +1,7 @@
 package instapaper
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -26,8 +27,12 @@ type Bookmark struct {
 
 // BookmarkListResponse represents the useful part of the API response for the bookmark list endpoint
 type BookmarkListResponse struct {
-	Bookmarks   []Bookmark
-	Highlights  []Highlight
+	Bookmarks  []Bookmark
+	Highlights []Highlight
+	// DeleteIDs lists the bookmarks passed in the "have" parameter which are no longer in the folder
+	DeleteIDs []int `json:"delete_ids"`
+	// User is the owner of the bookmarks
+	User        *User
 	RawResponse string
 }
 
@@ -61,14 +66,19 @@ type BookmarkAddRequestParams struct {
 	PrivateSourceName string
 }
 
-// BookmarkService is the implementation of the bookmark related parts of the API client, conforming to the BookmarkService interface
+// BookmarkService is the implementation of the bookmark related parts of the API client, conforming to the BookmarkAPI interface
 type BookmarkService struct {
-	Client Client
+	Client *Client
 }
 
 // List returns the list of bookmarks. By default it returns (maximum) 500 of the unread bookmarks
 // see BookmarkListRequestParams for filtering options
 func (svc *BookmarkService) List(p BookmarkListRequestParams) (*BookmarkListResponse, error) {
+	return svc.ListContext(context.Background(), p)
+}
+
+// ListContext is like List but the request is bound to ctx
+func (svc *BookmarkService) ListContext(ctx context.Context, p BookmarkListRequestParams) (*BookmarkListResponse, error) {
 	params := url.Values{}
 	params.Set("limit", strconv.Itoa(p.Limit))
 	if p.CustomHaveParam != "" {
@@ -91,7 +101,7 @@ func (svc *BookmarkService) List(p BookmarkListRequestParams) (*BookmarkListResp
 		params.Set("folder_id", p.Folder)
 	}
 
-	res, err := svc.Client.Call("/bookmarks/list", params)
+	res, err := svc.Client.CallContext(ctx, "/bookmarks/list", params)
 	if err != nil {
 		return &BookmarkListResponse{}, err
 	}
@@ -110,22 +120,27 @@ func (svc *BookmarkService) List(p BookmarkListRequestParams) (*BookmarkListResp
 	err = json.Unmarshal([]byte(bodyString), &bookmarkList)
 	if err != nil {
 		return &BookmarkListResponse{
-				RawResponse: bodyString,
-			}, &APIError{
-				StatusCode:   res.StatusCode,
-				Message:      err.Error(),
-				ErrorCode:    ErrUnmarshalError,
-				WrappedError: err,
-			}
+			RawResponse: bodyString,
+		}, &APIError{
+			StatusCode:   res.StatusCode,
+			Message:      err.Error(),
+			ErrorCode:    ErrUnmarshalError,
+			WrappedError: err,
+		}
 	}
 	return &bookmarkList, nil
 }
 
 // GetText returns the specified bookmark's processed text-view HTML, which is always text/html encoded as UTF-8.
 func (svc *BookmarkService) GetText(bookmarkID int) (string, error) {
+	return svc.GetTextContext(context.Background(), bookmarkID)
+}
+
+// GetTextContext is like GetText but the request is bound to ctx
+func (svc *BookmarkService) GetTextContext(ctx context.Context, bookmarkID int) (string, error) {
 	params := url.Values{}
 	params.Set("bookmark_id", strconv.Itoa(bookmarkID))
-	res, err := svc.Client.Call("/bookmarks/get_text", params)
+	res, err := svc.Client.CallContext(ctx, "/bookmarks/get_text", params)
 	if err != nil {
 		return "", err
 	}
@@ -144,50 +159,80 @@ func (svc *BookmarkService) GetText(bookmarkID int) (string, error) {
 
 // Star stars the specified bookmark
 func (svc *BookmarkService) Star(bookmarkID int) error {
+	return svc.StarContext(context.Background(), bookmarkID)
+}
+
+// StarContext is like Star but the request is bound to ctx
+func (svc *BookmarkService) StarContext(ctx context.Context, bookmarkID int) error {
 	params := url.Values{}
 	params.Set("bookmark_id", strconv.Itoa(bookmarkID))
-	_, err := svc.Client.Call("/bookmarks/star", params)
+	_, err := svc.Client.CallContext(ctx, "/bookmarks/star", params)
 	return err
 }
 
 // UnStar un-stars the specified bookmark
 func (svc *BookmarkService) UnStar(bookmarkID int) error {
+	return svc.UnStarContext(context.Background(), bookmarkID)
+}
+
+// UnStarContext is like UnStar but the request is bound to ctx
+func (svc *BookmarkService) UnStarContext(ctx context.Context, bookmarkID int) error {
 	params := url.Values{}
 	params.Set("bookmark_id", strconv.Itoa(bookmarkID))
-	_, err := svc.Client.Call("/bookmarks/unstar", params)
+	_, err := svc.Client.CallContext(ctx, "/bookmarks/unstar", params)
 	return err
 }
 
 // Archive archives the specified bookmark
 func (svc *BookmarkService) Archive(bookmarkID int) error {
+	return svc.ArchiveContext(context.Background(), bookmarkID)
+}
+
+// ArchiveContext is like Archive but the request is bound to ctx
+func (svc *BookmarkService) ArchiveContext(ctx context.Context, bookmarkID int) error {
 	params := url.Values{}
 	params.Set("bookmark_id", strconv.Itoa(bookmarkID))
-	_, err := svc.Client.Call("/bookmarks/archive", params)
+	_, err := svc.Client.CallContext(ctx, "/bookmarks/archive", params)
 	return err
 }
 
 // UnArchive un-archives the specified bookmark
 func (svc *BookmarkService) UnArchive(bookmarkID int) error {
+	return svc.UnArchiveContext(context.Background(), bookmarkID)
+}
+
+// UnArchiveContext is like UnArchive but the request is bound to ctx
+func (svc *BookmarkService) UnArchiveContext(ctx context.Context, bookmarkID int) error {
 	params := url.Values{}
 	params.Set("bookmark_id", strconv.Itoa(bookmarkID))
-	_, err := svc.Client.Call("/bookmarks/unarchive", params)
+	_, err := svc.Client.CallContext(ctx, "/bookmarks/unarchive", params)
 	return err
 }
 
 // DeletePermanently PERMANENTLY deletes the specified bookmark
 func (svc *BookmarkService) DeletePermanently(bookmarkID int) error {
+	return svc.DeletePermanentlyContext(context.Background(), bookmarkID)
+}
+
+// DeletePermanentlyContext is like DeletePermanently but the request is bound to ctx
+func (svc *BookmarkService) DeletePermanentlyContext(ctx context.Context, bookmarkID int) error {
 	params := url.Values{}
 	params.Set("bookmark_id", strconv.Itoa(bookmarkID))
-	_, err := svc.Client.Call("/bookmarks/delete", params)
+	_, err := svc.Client.CallContext(ctx, "/bookmarks/delete", params)
 	return err
 }
 
 // Move moves the specified bookmark to the specified folder
 func (svc *BookmarkService) Move(bookmarkID int, folderID string) error {
+	return svc.MoveContext(context.Background(), bookmarkID, folderID)
+}
+
+// MoveContext is like Move but the request is bound to ctx
+func (svc *BookmarkService) MoveContext(ctx context.Context, bookmarkID int, folderID string) error {
 	params := url.Values{}
 	params.Set("bookmark_id", strconv.Itoa(bookmarkID))
 	params.Set("folder_id", folderID)
-	_, err := svc.Client.Call("/bookmarks/move", params)
+	_, err := svc.Client.CallContext(ctx, "/bookmarks/move", params)
 	return err
 }
 
@@ -195,6 +240,11 @@ func (svc *BookmarkService) Move(bookmarkID int, folderID string) error {
 // progress is between 0.0 and 1.0 - a percentage
 // when - Unix timestamp - optionally specify when the update happened. If it's set to 0 the current timestamp is used.
 func (svc *BookmarkService) UpdateReadProgress(bookmarkID int, progress float32, when int64) error {
+	return svc.UpdateReadProgressContext(context.Background(), bookmarkID, progress, when)
+}
+
+// UpdateReadProgressContext is like UpdateReadProgress but the request is bound to ctx
+func (svc *BookmarkService) UpdateReadProgressContext(ctx context.Context, bookmarkID int, progress float32, when int64) error {
 	if when == 0 {
 		when = time.Now().Unix()
 	}
@@ -202,12 +252,17 @@ func (svc *BookmarkService) UpdateReadProgress(bookmarkID int, progress float32,
 	params.Set("bookmark_id", strconv.Itoa(bookmarkID))
 	params.Set("progress_timestamp", strconv.FormatInt(when, 10))
 	params.Set("progress", fmt.Sprintf("%f", progress))
-	_, err := svc.Client.Call("/bookmarks/update_read_progress", params)
+	_, err := svc.Client.CallContext(ctx, "/bookmarks/update_read_progress", params)
 	return err
 }
 
 // Add adds a new bookmark from the specified URL
 func (svc *BookmarkService) Add(p BookmarkAddRequestParams) (*Bookmark, error) {
+	return svc.AddContext(context.Background(), p)
+}
+
+// AddContext is like Add but the request is bound to ctx
+func (svc *BookmarkService) AddContext(ctx context.Context, p BookmarkAddRequestParams) (*Bookmark, error) {
 	params := url.Values{}
 	params.Set("url", p.URL)
 	if p.Description != "" {
@@ -228,7 +283,7 @@ func (svc *BookmarkService) Add(p BookmarkAddRequestParams) (*Bookmark, error) {
 	if p.PrivateSourceName != "" {
 		params.Set("is_private_from_source", p.PrivateSourceName)
 	}
-	res, err := svc.Client.Call("/bookmarks/add", params)
+	res, err := svc.Client.CallContext(ctx, "/bookmarks/add", params)
 	if err != nil {
 		return nil, err
 	}
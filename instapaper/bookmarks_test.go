@@ -1,10 +1,13 @@
 package instapaper
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/gomodule/oauth1/oauth"
@@ -12,7 +15,7 @@ import (
 
 var (
 	mux                *http.ServeMux
-	client             Client
+	client             *Client
 	server             *httptest.Server
 	defaultCredentials = &oauth.Credentials{
 		Token:  "Yolo",
@@ -143,6 +146,7 @@ func TestValidResponse(t *testing.T) {
 			  "type":"bookmark"
 		   }
 		],
+		"delete_ids":[654321],
 		"user":{
 		   "username":"nope@nope.com",
 		   "user_id":12345678,
@@ -177,6 +181,12 @@ func TestValidResponse(t *testing.T) {
 				Position:   0,
 			},
 		},
+		DeleteIDs: []int{654321},
+		User: &User{
+			ID:                   12345678,
+			Username:             "nope@nope.com",
+			SubscriptionIsActive: "1",
+		},
 	}
 
 	mux.HandleFunc("/bookmarks/list", func(w http.ResponseWriter, r *http.Request) {
@@ -196,3 +206,69 @@ func TestValidResponse(t *testing.T) {
 		t.Errorf("Expected the returned bookmark list to be %v, instead got %v", expectedResponse, bookmarkList)
 	}
 }
+
+func TestListAll(t *testing.T) {
+	setup()
+	defer teardown()
+	requests := 0
+	mux.HandleFunc("/bookmarks/list", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if folder := r.FormValue("folder_id"); folder != "archive" {
+			t.Errorf("expected the folder to be archive, got %v", folder)
+		}
+		have := map[string]bool{}
+		for _, id := range strings.Split(r.FormValue("have"), ",") {
+			have[id] = true
+		}
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		var page []string
+		for id := 1; id <= 5 && len(page) < limit; id++ {
+			if !have[strconv.Itoa(id)] {
+				page = append(page, fmt.Sprintf(`{"bookmark_id":%d,"type":"bookmark"}`, id))
+			}
+		}
+		// the already seen bookmark 1 is repeated, it must not show up twice
+		if len(have) > 1 {
+			page = append(page, `{"bookmark_id":1,"type":"bookmark"}`)
+		}
+		fmt.Fprintf(w, `{"bookmarks":[%s]}`, strings.Join(page, ","))
+	})
+	svc := BookmarkService{
+		Client: client,
+	}
+	it := svc.ListAll(context.Background(), FolderIDArchive)
+	it.pageSize = 2
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.Bookmark().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("expected every bookmark exactly once, got %v", ids)
+	}
+	// the last page only has the duplicate in it
+	if requests != 4 {
+		t.Errorf("expected 4 pages to be fetched, got %d", requests)
+	}
+}
+
+func TestListAllError(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/bookmarks/list", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `[{"error_code":1242,"message":"Invalid or missing folder_id"}]`)
+	})
+	svc := BookmarkService{
+		Client: client,
+	}
+	it := svc.ListAll(context.Background(), "nope")
+	if it.Next() {
+		t.Errorf("expected Next() to return false")
+	}
+	if apiErr, ok := it.Err().(*APIError); !ok || apiErr.ErrorCode != ErrInvalidFolderID {
+		t.Errorf("expected an invalid folder error, got %v", it.Err())
+	}
+}
@@ -1,23 +1,85 @@
 package instapaper
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/gomodule/oauth1/oauth"
 )
 
 const defaultBaseURL = "https://www.instapaper.com/api/1.1"
 
-// Client represents the API client and is used directly by the specific API endpoint client implementations
+// Client represents the API client. The services attached to it share the client through a pointer, so they all see
+// the credentials obtained by Authenticate, the same RateLimiter, RetryPolicy and so on:
+//
+//	client, _ := instapaper.NewClient(consumerID, consumerSecret, username, password)
+//	if err := client.Authenticate(); err != nil {
+//		...
+//	}
+//	bookmarks, err := client.Bookmarks.List(instapaper.DefaultBookmarkListRequestParams)
 type Client struct {
 	OAuthClient oauth.Client
 	Username    string
 	Password    string
 	Credentials *oauth.Credentials
 	BaseURL     string
+	// HTTPClient is used for the token exchange and every API call. http.DefaultClient is used when it's nil
+	HTTPClient *http.Client
+	// RetryPolicy controls retrying of failed calls, nil means no retries at all
+	RetryPolicy *RetryPolicy
+	// RateLimiter throttles calls client side, nil means no throttling. Copies of the client share the same limiter
+	RateLimiter *RateLimiter
+	// TokenStore persists the credentials across runs, see Authenticate
+	TokenStore TokenStore
+	// VerifyOnAuthenticate makes Authenticate call verify_credentials and fill in User
+	VerifyOnAuthenticate bool
+	// User is the owner of the credentials, only set by Authenticate when VerifyOnAuthenticate is on
+	User *User
+
+	// services talking to the API through this client, set up by NewClient
+	Bookmarks  *BookmarkService
+	Folders    *FolderService
+	Highlights *HighlightService
+	Account    *AccountService
+}
+
+// Option customizes a Client created by NewClient
+type Option func(*Client)
+
+// WithHTTPClient makes the client use httpClient instead of http.DefaultClient - useful for timeouts, proxies or custom transports
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.HTTPClient = httpClient
+	}
+}
+
+// WithBaseURL points the client - including the xAuth token exchange - to a different API root than the default one
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.BaseURL = baseURL
+		c.OAuthClient.TokenRequestURI = baseURL + "/oauth/access_token"
+	}
+}
+
+// WithVerifyOnAuthenticate makes Authenticate verify the credentials and populate the client's User
+func WithVerifyOnAuthenticate() Option {
+	return func(c *Client) {
+		c.VerifyOnAuthenticate = true
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		if c.OAuthClient.Header == nil {
+			c.OAuthClient.Header = http.Header{}
+		}
+		c.OAuthClient.Header.Set("User-Agent", userAgent)
+	}
 }
 
 // ClientIf represents the interface an Instapaper API client needs to implement
@@ -25,9 +87,10 @@ type ClientIf interface {
 	Authenticate() error
 }
 
-// NewClient configures a new Client and returns it. This is the preferred way to get a new client.
-func NewClient(consumerID string, consumerSecret string, username string, password string) (Client, error) {
-	return Client{
+// NewClient configures a new Client along with its services and returns it. This is the preferred way to get a new client.
+// The optional opts are applied in order on top of the defaults.
+func NewClient(consumerID string, consumerSecret string, username string, password string, opts ...Option) (*Client, error) {
+	client := &Client{
 		OAuthClient: oauth.Client{
 			SignatureMethod: oauth.HMACSHA1,
 			Credentials: oauth.Credentials{
@@ -39,31 +102,126 @@ func NewClient(consumerID string, consumerSecret string, username string, passwo
 		Username: username,
 		Password: password,
 		BaseURL:  defaultBaseURL,
-	}, nil
+	}
+	client.Bookmarks = &BookmarkService{Client: client}
+	client.Folders = &FolderService{Client: client}
+	client.Highlights = &HighlightService{Client: client}
+	client.Account = &AccountService{Client: client}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client, nil
 }
 
-// Authenticate uses the client ID and secret plus the username/password to get oAuth tokens with which it can make authenticated calls in the future
+// Authenticate uses the client ID and secret plus the username/password to get oAuth tokens with which it can make authenticated calls in the future.
+// If the client has a TokenStore, previously saved tokens are used as they are - no username/password needed - and freshly obtained ones are saved.
+// With VerifyOnAuthenticate the tokens are checked right away and User is populated.
 func (svc *Client) Authenticate() error {
-	credentials, _, err := svc.OAuthClient.RequestTokenXAuth(nil, nil, svc.Username, svc.Password)
+	return svc.AuthenticateContext(context.Background())
+}
+
+// AuthenticateContext is like Authenticate but the token exchange is bound to ctx
+func (svc *Client) AuthenticateContext(ctx context.Context) error {
+	if svc.TokenStore != nil {
+		credentials, err := svc.TokenStore.Load()
+		if err != nil {
+			return err
+		}
+		if credentials != nil {
+			svc.Credentials = credentials
+			return svc.verifyOnAuthenticate(ctx)
+		}
+	}
+	credentials, _, err := svc.OAuthClient.RequestTokenXAuthContext(svc.httpContext(ctx), nil, svc.Username, svc.Password)
 	if err != nil {
 		return err
 	}
 	svc.Credentials = credentials
+	if svc.TokenStore != nil {
+		if err := svc.TokenStore.Save(credentials); err != nil {
+			return err
+		}
+	}
+	return svc.verifyOnAuthenticate(ctx)
+}
+
+func (svc *Client) verifyOnAuthenticate(ctx context.Context) error {
+	if !svc.VerifyOnAuthenticate {
+		return nil
+	}
+	user, err := svc.verifyCredentials(ctx)
+	if err != nil {
+		return err
+	}
+	svc.User = user
 	return nil
 }
 
 // Call makes a call to the Instapaper API on the specific path with the given call parameters. It handles errors converting them to an APIError instance
 func (svc *Client) Call(path string, params url.Values) (*http.Response, error) {
+	return svc.CallContext(context.Background(), path, params)
+}
+
+// CallContext is like Call but the request is bound to ctx. If ctx is canceled or its deadline passes before the
+// response arrives, the returned APIError has the ErrCanceled code and wraps ctx.Err().
+// Every attempt waits for the client's RateLimiter first and failed calls are retried according to the client's RetryPolicy.
+func (svc *Client) CallContext(ctx context.Context, path string, params url.Values) (*http.Response, error) {
 	if svc.Credentials == nil {
 		return nil, &APIError{
 			Message:   "Please call Authenticate() first",
 			ErrorCode: ErrNotAuthenticated,
+			Path:      path,
+		}
+	}
+	policy := svc.RetryPolicy
+	for attempt := 1; ; attempt++ {
+		if svc.RateLimiter != nil {
+			if err := svc.RateLimiter.WaitN(ctx, svc.RateLimiter.weight(path)); err != nil {
+				return nil, withPath(canceledError(err), path)
+			}
+		}
+		res, err := svc.call(ctx, path, params)
+		if err == nil || policy == nil || attempt >= policy.MaxAttempts || !policy.shouldRetry(path, err) {
+			return res, err
+		}
+		wait := policy.backoff(attempt, err)
+		if policy.OnRetry != nil {
+			policy.OnRetry(RetryEvent{
+				Path:    path,
+				Attempt: attempt,
+				Err:     err,
+				Wait:    wait,
+			})
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, withPath(canceledError(ctx.Err()), path)
+		case <-timer.C:
 		}
 	}
-	res, err := svc.OAuthClient.Post(nil, svc.Credentials, svc.BaseURL+path, params)
-	if err == nil && res.StatusCode == 200 {
+}
+
+// call makes a single attempt at calling the API
+func (svc *Client) call(ctx context.Context, path string, params url.Values) (*http.Response, error) {
+	res, err := svc.OAuthClient.PostContext(svc.httpContext(ctx), svc.Credentials, svc.BaseURL+path, params)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, withPath(canceledError(ctxErr), path)
+		}
+		return nil, &APIError{
+			Message:      err.Error(),
+			ErrorCode:    ErrHTTPError,
+			WrappedError: err,
+			Path:         path,
+		}
+	}
+	if res.StatusCode == 200 {
 		return res, nil
 	}
+	defer res.Body.Close()
+	retryAfter := parseRetryAfter(res.Header)
 	var apiError []APIError
 	bodyBytes, err := ioutil.ReadAll(res.Body)
 	// there was a "low level" http error
@@ -73,6 +231,8 @@ func (svc *Client) Call(path string, params url.Values) (*http.Response, error)
 			Message:      err.Error(),
 			ErrorCode:    ErrHTTPError,
 			WrappedError: err,
+			RetryAfter:   retryAfter,
+			Path:         path,
 		}
 	}
 	err = json.Unmarshal(bodyBytes, &apiError)
@@ -82,8 +242,34 @@ func (svc *Client) Call(path string, params url.Values) (*http.Response, error)
 			Message:      err.Error(),
 			ErrorCode:    ErrUnmarshalError,
 			WrappedError: err,
+			RetryAfter:   retryAfter,
+			Path:         path,
 		}
 	}
 	apiError[0].StatusCode = res.StatusCode
+	apiError[0].RetryAfter = retryAfter
+	apiError[0].Path = path
 	return nil, &apiError[0]
 }
+
+func canceledError(err error) *APIError {
+	return &APIError{
+		Message:      err.Error(),
+		ErrorCode:    ErrCanceled,
+		WrappedError: err,
+	}
+}
+
+// withPath records the endpoint on err
+func withPath(err *APIError, path string) *APIError {
+	err.Path = path
+	return err
+}
+
+// httpContext attaches the configured HTTPClient to ctx, this is how the oauth package picks up the client to use
+func (svc *Client) httpContext(ctx context.Context) context.Context {
+	if svc.HTTPClient == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, oauth.HTTPClient, svc.HTTPClient)
+}
@@ -1,11 +1,15 @@
 package instapaper
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gomodule/oauth1/oauth"
 )
@@ -62,6 +66,40 @@ func TestAuthSuccess(t *testing.T) {
 	}
 }
 
+func TestServicesShareClient(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "oauth_token=token&oauth_token_secret=secret")
+	})
+	mux.HandleFunc("/folders/list", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Authorization"), `oauth_token="token"`) {
+			t.Errorf("expected the request to be signed with the new token, got %v", r.Header.Get("Authorization"))
+		}
+		fmt.Fprint(w, "[]")
+	})
+	client.Credentials = nil
+	if _, err := client.Folders.List(); err == nil {
+		t.Errorf("expected the call to fail before authentication")
+	}
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("expected Authenticate() to succeed, got %v", err)
+	}
+	if _, err := client.Folders.List(); err != nil {
+		t.Errorf("expected the service to see the new credentials, got %v", err)
+	}
+	for name, c := range map[string]*Client{
+		"Bookmarks":  client.Bookmarks.Client,
+		"Folders":    client.Folders.Client,
+		"Highlights": client.Highlights.Client,
+		"Account":    client.Account.Client,
+	} {
+		if c != client {
+			t.Errorf("expected %s to share the client", name)
+		}
+	}
+}
+
 func TestAuthFailure(t *testing.T) {
 	setup()
 	defer teardown()
@@ -94,6 +132,7 @@ func TestErrorHandling(t *testing.T) {
 		Message:      "test error message",
 		StatusCode:   http.StatusInternalServerError,
 		WrappedError: nil,
+		Path:         "/errortest",
 	}
 	mux.HandleFunc("/errortest", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -104,3 +143,247 @@ func TestErrorHandling(t *testing.T) {
 		t.Errorf("expected the error to be %v, got %v", expectedError, err)
 	}
 }
+
+func TestErrorClasses(t *testing.T) {
+	setup()
+	defer teardown()
+	respond := func(status, code int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `[{"error_code":%d,"message":"nope"}]`, code)
+		}
+	}
+	mux.HandleFunc("/bookmarks/add", respond(http.StatusBadRequest, 1250))
+	mux.HandleFunc("/folders/add", respond(http.StatusBadRequest, 1250))
+	mux.HandleFunc("/folders/delete", respond(http.StatusBadRequest, 1250))
+	mux.HandleFunc("/bookmarks/star", respond(http.StatusBadRequest, ErrInvalidBookmarkID))
+	mux.HandleFunc("/bookmarks/list", respond(http.StatusBadRequest, ErrRateLimitExceeded))
+	mux.HandleFunc("/folders/list", respond(http.StatusBadRequest, ErrNotPremiumAccount))
+
+	_, err := client.Bookmarks.Add(BookmarkAddRequestParams{URL: "https://example.com"})
+	if !errors.Is(err, ErrServer) || errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("expected 1250 on /bookmarks/add to be a service error, got %v", err)
+	}
+	_, err = client.Folders.Add("")
+	if !errors.Is(err, ErrInvalidRequest) || errors.Is(err, ErrServer) {
+		t.Errorf("expected 1250 on /folders/add to be an invalid request, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Path != "/folders/add" {
+		t.Errorf("expected an APIError for /folders/add, got %v", err)
+	}
+	if err := client.Folders.Delete("100"); !errors.Is(err, ErrServer) || errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("expected 1250 on /folders/delete to be a service error, got %v", err)
+	}
+	if err := client.Bookmarks.Star(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	_, err = client.Bookmarks.List(DefaultBookmarkListRequestParams)
+	if !errors.Is(err, ErrRateLimited) || !IsRetryable(err) {
+		t.Errorf("expected a retryable ErrRateLimited, got %v", err)
+	}
+	_, err = client.Folders.List()
+	if !errors.Is(err, ErrPremiumRequired) || IsRetryable(err) {
+		t.Errorf("expected a final ErrPremiumRequired, got %v", err)
+	}
+	wrapped := fmt.Errorf("listing: %w", err)
+	if !errors.Is(wrapped, ErrPremiumRequired) {
+		t.Errorf("expected the class to survive wrapping, got %v", wrapped)
+	}
+}
+
+func TestCallContextCanceled(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/bookmarks/list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "{}")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc := BookmarkService{
+		Client: client,
+	}
+	_, err := svc.ListContext(ctx, DefaultBookmarkListRequestParams)
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected an *APIError, got %v", err)
+	}
+	if apiErr.ErrorCode != ErrCanceled {
+		t.Errorf("expected error code %d, got %d", ErrCanceled, apiErr.ErrorCode)
+	}
+	if apiErr.WrappedError != context.Canceled {
+		t.Errorf("expected the wrapped error to be %v, got %v", context.Canceled, apiErr.WrappedError)
+	}
+	if !errors.Is(err, context.Canceled) || IsRetryable(err) {
+		t.Errorf("expected a final error unwrapping to %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestCallNetworkError(t *testing.T) {
+	setup()
+	teardown()
+	_, err := client.Call("/bookmarks/list", nil)
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected an *APIError, got %v", err)
+	}
+	if apiErr.ErrorCode != ErrHTTPError {
+		t.Errorf("expected error code %d, got %d", ErrHTTPError, apiErr.ErrorCode)
+	}
+}
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (rt *recordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, r)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestClientOptions(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "oauth_token=token&oauth_token_secret=secret")
+	})
+	mux.HandleFunc("/folders/list", func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != "test-agent" {
+			t.Errorf("expected the User-Agent to be 'test-agent', got %v", ua)
+		}
+		fmt.Fprint(w, "[]")
+	})
+	transport := &recordingTransport{}
+	optClient, _ := NewClient("client_id", "client_secret", "username", "password",
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithBaseURL(server.URL),
+		WithUserAgent("test-agent"),
+	)
+	if optClient.OAuthClient.TokenRequestURI != server.URL+"/oauth/access_token" {
+		t.Errorf("expected the TokenRequestURI to follow the base URL, got %v", optClient.OAuthClient.TokenRequestURI)
+	}
+	if err := optClient.Authenticate(); err != nil {
+		t.Fatalf("expected Authenticate() to succeed, got %v", err)
+	}
+	svc := FolderService{
+		Client: optClient,
+	}
+	if _, err := svc.List(); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if len(transport.requests) != 2 {
+		t.Errorf("expected both requests to go through the custom transport, got %d", len(transport.requests))
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	setup()
+	defer teardown()
+	attempts := 0
+	mux.HandleFunc("/bookmarks/list", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `[{"error_code":1040,"message":"Rate-limit exceeded"}]`)
+			return
+		}
+		fmt.Fprint(w, "{}")
+	})
+	var events []RetryEvent
+	client.RetryPolicy = &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		OnRetry: func(e RetryEvent) {
+			events = append(events, e)
+		},
+	}
+	_, err := client.Call("/bookmarks/list", nil)
+	if err != nil {
+		t.Errorf("expected the call to succeed after retrying, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+	if len(events) != 2 || events[0].Attempt != 1 || events[1].Attempt != 2 {
+		t.Errorf("expected the OnRetry hook to see attempts 1 and 2, got %v", events)
+	}
+}
+
+func TestRetryPolicySkipsNonIdempotent(t *testing.T) {
+	setup()
+	defer teardown()
+	attempts := 0
+	mux.HandleFunc("/folders/add", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `[{"error_code":1500,"message":"Unexpected service error"}]`)
+	})
+	client.RetryPolicy = &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	}
+	_, err := client.Call("/folders/add", nil)
+	if err == nil {
+		t.Errorf("expected err NOT to be nil")
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter(http.Header{"Retry-After": []string{"120"}}); d != 2*time.Minute {
+		t.Errorf("expected 2m, got %v", d)
+	}
+	when := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d := parseRetryAfter(http.Header{"Retry-After": []string{when}}); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("expected about an hour, got %v", d)
+	}
+	if d := parseRetryAfter(http.Header{}); d != 0 {
+		t.Errorf("expected no wait, got %v", d)
+	}
+}
+
+func TestVerifyOnAuthenticate(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "oauth_token=token&oauth_token_secret=secret")
+	})
+	mux.HandleFunc("/account/verify_credentials", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"type":"user","user_id":54321,"username":"nope@nope.com","subscription_is_active":"1"}]`)
+	})
+	client.VerifyOnAuthenticate = true
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("expected Authenticate() to succeed, got %v", err)
+	}
+	expectedUser := &User{
+		ID:                   54321,
+		Username:             "nope@nope.com",
+		SubscriptionIsActive: "1",
+	}
+	if !reflect.DeepEqual(client.User, expectedUser) {
+		t.Errorf("expected the user to be %v, got %v", expectedUser, client.User)
+	}
+	if !client.User.HasActiveSubscription() {
+		t.Errorf("expected the subscription to be active")
+	}
+}
+
+func TestVerifyCredentialsInvalid(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/account/verify_credentials", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `[{"error_code":403,"message":"Not logged in"}]`)
+	})
+	svc := AccountService{
+		Client: client,
+	}
+	user, err := svc.VerifyCredentials()
+	if err == nil || user != nil {
+		t.Errorf("expected VerifyCredentials() to fail, got %v", user)
+	}
+}
@@ -1,6 +1,11 @@
 package instapaper
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
 
 const (
 	// General errors:
@@ -23,7 +28,7 @@ const (
 
 	// Folder errors:
 
-	ErrInvalidTitle              = 1250 // Invalid or missing title
+	ErrInvalidTitle              = 1250 // Invalid or missing title - same code as ErrUnexpected, see APIError.Class
 	ErrDuplicateFolder           = 1251 // User already has a folder with this title
 	ErrCannotAddBookmarkToFolder = 1252 // Cannot add bookmarks to this folder
 
@@ -42,16 +47,109 @@ const (
 	ErrNotAuthenticated = 666 // The client did not authenticate
 	ErrUnmarshalError   = 667 // Cannot unmarshal the response from Instapaper's API
 	ErrHTTPError        = 668 // A generic HTTP error
+	ErrCanceled         = 669 // The request's context was canceled or its deadline exceeded
+)
+
+// Classes of errors, an APIError matches the one its code belongs to through errors.Is:
+//
+//	if errors.Is(err, instapaper.ErrNotFound) {
+//		...
+//	}
+var (
+	ErrRateLimited     = errors.New("instapaper: rate limit exceeded")              // ErrRateLimitExceeded
+	ErrPremiumRequired = errors.New("instapaper: premium account required")         // ErrNotPremiumAccount
+	ErrSuspended       = errors.New("instapaper: application suspended")            // ErrApplicationSuspended
+	ErrUnauthorized    = errors.New("instapaper: not authenticated")                // ErrNotAuthenticated, 401 and 403 responses
+	ErrNotFound        = errors.New("instapaper: no such bookmark or folder")       // ErrInvalidBookmarkID, ErrInvalidFolderID
+	ErrInvalidRequest  = errors.New("instapaper: invalid request")                  // invalid parameters, including ErrInvalidTitle
+	ErrRejected        = errors.New("instapaper: bookmark rejected by the website") // ErrFullContentRequired, ErrDomainNotSupported, ErrSuppliedContentRequired
+	ErrDuplicate       = errors.New("instapaper: already exists")                   // ErrDuplicateFolder, ErrDuplicateHighlight
+	ErrServer          = errors.New("instapaper: service error")                    // ErrGeneric, ErrTextGen, ErrUnexpected and 5xx responses
 )
 
+var errorClasses = map[int]error{
+	ErrRateLimitExceeded:         ErrRateLimited,
+	ErrNotPremiumAccount:         ErrPremiumRequired,
+	ErrApplicationSuspended:      ErrSuspended,
+	ErrFullContentRequired:       ErrRejected,
+	ErrDomainNotSupported:        ErrRejected,
+	ErrInvalidURL:                ErrInvalidRequest,
+	ErrInvalidBookmarkID:         ErrNotFound,
+	ErrInvalidFolderID:           ErrNotFound,
+	ErrInvalidProgress:           ErrInvalidRequest,
+	ErrInvalidProgressTimestamp:  ErrInvalidRequest,
+	ErrSuppliedContentRequired:   ErrRejected,
+	ErrDuplicateFolder:           ErrDuplicate,
+	ErrCannotAddBookmarkToFolder: ErrInvalidRequest,
+	ErrGeneric:                   ErrServer,
+	ErrTextGen:                   ErrServer,
+	ErrEmptyText:                 ErrInvalidRequest,
+	ErrDuplicateHighlight:        ErrDuplicate,
+	ErrNotAuthenticated:          ErrUnauthorized,
+}
+
 // APIError represents an error returned by the Instapaper API - a numeric code and a message
 type APIError struct {
 	ErrorCode    int `json:"error_code"`
 	StatusCode   int
 	Message      string
 	WrappedError error
+	// RetryAfter is the wait requested by the server through the Retry-After header, if any
+	RetryAfter time.Duration
+	// Path is the endpoint that was called, e.g. "/bookmarks/add". Some codes mean different things depending on it
+	Path string
 }
 
 func (r *APIError) Error() string {
 	return fmt.Sprintf("status %d: err #%d - %v", r.StatusCode, r.ErrorCode, r.Message)
 }
+
+// Unwrap returns the underlying error, e.g. context.Canceled for ErrCanceled or the network error for ErrHTTPError
+func (r *APIError) Unwrap() error {
+	return r.WrappedError
+}
+
+// Is makes errors.Is match the error class of the code, e.g. ErrNotFound for ErrInvalidBookmarkID
+func (r *APIError) Is(target error) bool {
+	return target != nil && r.Class() == target
+}
+
+// Class returns the error class of the code - one of ErrRateLimited, ErrNotFound and the like - or nil if it has none.
+// 1250 is both ErrUnexpected and ErrInvalidTitle, it's ErrInvalidTitle only when adding a folder
+func (r *APIError) Class() error {
+	if r.ErrorCode == ErrUnexpected {
+		if r.Path == "/folders/add" {
+			return ErrInvalidRequest // ErrInvalidTitle
+		}
+		return ErrServer
+	}
+	if class, ok := errorClasses[r.ErrorCode]; ok {
+		return class
+	}
+	switch {
+	case r.StatusCode == http.StatusUnauthorized || r.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case r.StatusCode >= 500:
+		return ErrServer
+	}
+	return nil
+}
+
+// IsRetryable tells whether the call failing with err is worth repeating: it was rate limited, the service failed or
+// the network did. Whether repeating the call is safe is up to the caller, see RetryPolicy for the endpoints it retries
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode {
+	case ErrRateLimitExceeded, ErrGeneric:
+		return true
+	case ErrHTTPError:
+		// no status code means the request didn't make it to the server or the connection broke
+		return apiErr.StatusCode == 0 || apiErr.StatusCode >= 500
+	case ErrCanceled, ErrNotAuthenticated:
+		return false
+	}
+	return apiErr.StatusCode >= 500
+}
@@ -1,8 +1,8 @@
 package instapaper
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/url"
 )
@@ -15,6 +15,8 @@ type Folder struct {
 	DisplayTitle string `json:"display_title"`
 	SyncToMobile int    `json:"sync_to_mobile"`
 	Position     json.Number
+	// BuiltIn is true for the unread, starred and archive folders, it's never set by the API
+	BuiltIn bool `json:"-"`
 }
 
 // FolderIDUnread is the default folder - unread bookmarks
@@ -26,14 +28,26 @@ const FolderIDStarred = "starred"
 // FolderIDArchive is a built-in folder for archived bookmarks
 const FolderIDArchive = "archive"
 
+// BuiltInFolders are the folders every account has, in the order Instapaper shows them
+var BuiltInFolders = []Folder{
+	{ID: FolderIDUnread, Title: "Unread", Slug: FolderIDUnread, DisplayTitle: "Home", BuiltIn: true},
+	{ID: FolderIDStarred, Title: "Starred", Slug: FolderIDStarred, DisplayTitle: "Liked", BuiltIn: true},
+	{ID: FolderIDArchive, Title: "Archive", Slug: FolderIDArchive, DisplayTitle: "Archive", BuiltIn: true},
+}
+
 // FolderService encapsulates all folder operations
 type FolderService struct {
-	Client Client
+	Client *Client
 }
 
 // List returns the list of *custom created* folders. It does not return any of the built in ones!
 func (svc *FolderService) List() ([]Folder, error) {
-	res, err := svc.Client.Call("/folders/list", nil)
+	return svc.ListContext(context.Background())
+}
+
+// ListContext is like List but the request is bound to ctx
+func (svc *FolderService) ListContext(ctx context.Context) ([]Folder, error) {
+	res, err := svc.Client.CallContext(ctx, "/folders/list", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -59,11 +73,32 @@ func (svc *FolderService) List() ([]Folder, error) {
 	return folderList, nil
 }
 
+// ListAll returns every folder: the built-in ones first (see BuiltInFolders) followed by the custom ones
+func (svc *FolderService) ListAll() ([]Folder, error) {
+	return svc.ListAllContext(context.Background())
+}
+
+// ListAllContext is like ListAll but the request is bound to ctx
+func (svc *FolderService) ListAllContext(ctx context.Context) ([]Folder, error) {
+	custom, err := svc.ListContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	folders := make([]Folder, 0, len(BuiltInFolders)+len(custom))
+	folders = append(folders, BuiltInFolders...)
+	return append(folders, custom...), nil
+}
+
 // Add creates a folder and returns with it if there wasn't already one with the same title - in that case it returns an error
 func (svc *FolderService) Add(title string) (*Folder, error) {
+	return svc.AddContext(context.Background(), title)
+}
+
+// AddContext is like Add but the request is bound to ctx
+func (svc *FolderService) AddContext(ctx context.Context, title string) (*Folder, error) {
 	params := url.Values{}
 	params.Set("title", title)
-	res, err := svc.Client.Call("/folders/add", params)
+	res, err := svc.Client.CallContext(ctx, "/folders/add", params)
 	if err != nil {
 		return nil, err
 	}
@@ -91,9 +126,14 @@ func (svc *FolderService) Add(title string) (*Folder, error) {
 
 // Delete removes a folder and moves all of its bookmark entries to the archive
 func (svc *FolderService) Delete(folderID string) error {
+	return svc.DeleteContext(context.Background(), folderID)
+}
+
+// DeleteContext is like Delete but the request is bound to ctx
+func (svc *FolderService) DeleteContext(ctx context.Context, folderID string) error {
 	params := url.Values{}
 	params.Set("folder_id", folderID)
-	_, err := svc.Client.Call("/folders/delete", params)
+	_, err := svc.Client.CallContext(ctx, "/folders/delete", params)
 	if err != nil {
 		return err
 	}
@@ -107,9 +147,14 @@ func (svc *FolderService) Delete(folderID string) error {
 // You should include all folders for consistency.
 // !!!No errors returned for missing or invalid folders!!!
 func (svc *FolderService) SetOrder(folderOrderlist string) ([]Folder, error) {
+	return svc.SetOrderContext(context.Background(), folderOrderlist)
+}
+
+// SetOrderContext is like SetOrder but the request is bound to ctx
+func (svc *FolderService) SetOrderContext(ctx context.Context, folderOrderlist string) ([]Folder, error) {
 	params := url.Values{}
 	params.Set("order", folderOrderlist)
-	res, err := svc.Client.Call("/folders/set_order", params)
+	res, err := svc.Client.CallContext(ctx, "/folders/set_order", params)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +177,5 @@ func (svc *FolderService) SetOrder(folderOrderlist string) ([]Folder, error) {
 			WrappedError: err,
 		}
 	}
-	fmt.Println(string(bodyBytes))
 	return folderList, nil
 }
@@ -1,6 +1,7 @@
 package instapaper
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -18,14 +19,20 @@ type Highlight struct {
 	Position   int
 }
 
+// HighlightService encapsulates all highlight operations
 type HighlightService struct {
-	Client Client
+	Client *Client
 }
 
 // List fetches all highlights for the specified bookmark
 func (svc *HighlightService) List(bookmarkID int) ([]Highlight, error) {
+	return svc.ListContext(context.Background(), bookmarkID)
+}
+
+// ListContext is like List but the request is bound to ctx
+func (svc *HighlightService) ListContext(ctx context.Context, bookmarkID int) ([]Highlight, error) {
 	path := fmt.Sprintf("/bookmarks/%d/highlights", bookmarkID)
-	res, err := svc.Client.Call(path, nil)
+	res, err := svc.Client.CallContext(ctx, path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -53,8 +60,13 @@ func (svc *HighlightService) List(bookmarkID int) ([]Highlight, error) {
 
 // Delete removes the specified highlight
 func (svc *HighlightService) Delete(highlightID int) error {
+	return svc.DeleteContext(context.Background(), highlightID)
+}
+
+// DeleteContext is like Delete but the request is bound to ctx
+func (svc *HighlightService) DeleteContext(ctx context.Context, highlightID int) error {
 	path := fmt.Sprintf("/highlights/%d/delete", highlightID)
-	_, err := svc.Client.Call(path, nil)
+	_, err := svc.Client.CallContext(ctx, path, nil)
 	if err != nil {
 		return err
 	}
@@ -63,11 +75,16 @@ func (svc *HighlightService) Delete(highlightID int) error {
 
 // Add adds a highlight for the specified bookmark
 func (svc *HighlightService) Add(bookmarkID int, text string, position int) (*Highlight, error) {
+	return svc.AddContext(context.Background(), bookmarkID, text, position)
+}
+
+// AddContext is like Add but the request is bound to ctx
+func (svc *HighlightService) AddContext(ctx context.Context, bookmarkID int, text string, position int) (*Highlight, error) {
 	path := fmt.Sprintf("/bookmarks/%d/highlight", bookmarkID)
 	params := url.Values{}
 	params.Set("text", text)
 	params.Set("position", strconv.Itoa(position))
-	res, err := svc.Client.Call(path, params)
+	res, err := svc.Client.CallContext(ctx, path, params)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,123 @@
+package instapaper
+
+import "context"
+
+// BookmarkIterator walks all bookmarks of a folder page by page, see BookmarkService.ListAll.
+// Typical usage:
+//
+//	it := svc.ListAll(ctx, FolderIDUnread)
+//	for it.Next() {
+//		bookmark := it.Bookmark()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type BookmarkIterator struct {
+	ctx      context.Context
+	api      BookmarkAPI
+	folder   string
+	pageSize int
+	seen     map[int]bool
+	have     []Bookmark
+	page     []Bookmark
+	current  Bookmark
+	done     bool
+	err      error
+
+	// highlights of the fetched bookmarks, they come along with the pages
+	highlights     []Highlight
+	seenHighlights map[int]bool
+}
+
+// ListAll returns an iterator over every bookmark in the folder. Pages are fetched lazily, each request tells the API
+// which bookmarks were already seen through the "have" parameter, so the iteration goes past the 500 bookmark limit of List.
+// Bookmarks are returned at most once. The iteration stops at the first error or when ctx is done.
+func (svc *BookmarkService) ListAll(ctx context.Context, folder string) *BookmarkIterator {
+	return IterateBookmarks(ctx, svc, folder)
+}
+
+// IterateBookmarks returns an iterator over every bookmark in the folder, listed page by page through api.ListContext.
+// It's how other BookmarkAPI implementations - mocks, caches - can provide ListAll
+func IterateBookmarks(ctx context.Context, api BookmarkAPI, folder string) *BookmarkIterator {
+	return &BookmarkIterator{
+		ctx:            ctx,
+		api:            api,
+		folder:         folder,
+		pageSize:       DefaultBookmarkListRequestParams.Limit,
+		seen:           map[int]bool{},
+		seenHighlights: map[int]bool{},
+	}
+}
+
+// Next advances to the next bookmark, fetching the next page if needed. It returns false when there are no more bookmarks or an error occurred
+func (it *BookmarkIterator) Next() bool {
+	for {
+		if it.err != nil {
+			return false
+		}
+		if len(it.page) > 0 {
+			it.current = it.page[0]
+			it.page = it.page[1:]
+			return true
+		}
+		if it.done {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = canceledError(err)
+			return false
+		}
+		it.fetch()
+	}
+}
+
+// Bookmark returns the current bookmark, only valid after Next returned true
+func (it *BookmarkIterator) Bookmark() Bookmark {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *BookmarkIterator) Err() error {
+	return it.err
+}
+
+// Highlights returns the highlights of the bookmarks fetched so far - once the iteration is over that's every highlight in the folder
+func (it *BookmarkIterator) Highlights() []Highlight {
+	return it.highlights
+}
+
+func (it *BookmarkIterator) fetch() {
+	res, err := it.api.ListContext(it.ctx, BookmarkListRequestParams{
+		Limit:  it.pageSize,
+		Skip:   it.have,
+		Folder: it.folder,
+	})
+	if err != nil {
+		it.err = err
+		return
+	}
+	if res == nil {
+		it.done = true
+		return
+	}
+	for _, bookmark := range res.Bookmarks {
+		if it.seen[bookmark.ID] {
+			continue
+		}
+		it.seen[bookmark.ID] = true
+		it.page = append(it.page, bookmark)
+		it.have = append(it.have, Bookmark{ID: bookmark.ID})
+	}
+	for _, highlight := range res.Highlights {
+		if it.seenHighlights[highlight.ID] {
+			continue
+		}
+		it.seenHighlights[highlight.ID] = true
+		it.highlights = append(it.highlights, highlight)
+	}
+	// a short page is the last one, a page of nothing but duplicates means the API ignored "have" - stop instead of looping forever
+	if len(res.Bookmarks) < it.pageSize || len(it.page) == 0 {
+		it.done = true
+	}
+}
@@ -0,0 +1,129 @@
+package instapaper
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket smoothing out bursts of API calls. It's meant to be created once and set on a Client,
+// every service built from that client then shares it, so concurrent goroutines all draw from the same bucket.
+// Calls block in Wait until enough tokens are available instead of failing.
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens added per second
+	burst   float64
+	tokens  float64
+	last    time.Time
+	weights map[string]int
+}
+
+// NewRateLimiter returns a limiter allowing ratePerSecond calls on average with bursts of up to burst calls.
+// The bucket starts full.
+func NewRateLimiter(ratePerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    ratePerSecond,
+		burst:   float64(burst),
+		tokens:  float64(burst),
+		last:    time.Now(),
+		weights: map[string]int{},
+	}
+}
+
+// WithRateLimiter makes every call of the client wait for the limiter first
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.RateLimiter = limiter
+	}
+}
+
+var numericPathSegment = regexp.MustCompile(`/\d+(/|$)`)
+
+// endpointOf turns a concrete path into its endpoint name, e.g. /bookmarks/123/highlights -> /bookmarks/{id}/highlights
+func endpointOf(path string) string {
+	return numericPathSegment.ReplaceAllString(path, "/{id}$1")
+}
+
+// SetWeight sets how many tokens a call to the endpoint consumes, the default is 1.
+// Endpoints with IDs in their path are named with an {id} placeholder, e.g. /bookmarks/{id}/highlights
+func (l *RateLimiter) SetWeight(endpoint string, weight int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.weights[endpoint] = weight
+}
+
+func (l *RateLimiter) weight(path string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w, ok := l.weights[endpointOf(path)]; ok {
+		return w
+	}
+	return 1
+}
+
+// Wait blocks until a single token is available or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+// WaitN blocks until n tokens are available or ctx is done. If ctx is done first no tokens are consumed.
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	wait := l.reserve(float64(n))
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel(float64(n))
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes n tokens - possibly going into debt - and returns how long the caller has to wait for the debt to be repaid
+func (l *RateLimiter) reserve(n float64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	l.tokens -= n
+	if l.tokens >= 0 {
+		return 0
+	}
+	if l.rate <= 0 {
+		// nothing will ever refill the bucket, wait "forever" - or rather until the context is done
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+func (l *RateLimiter) cancel(n float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	l.tokens += n
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+func (l *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	l.last = now
+	if elapsed <= 0 {
+		return
+	}
+	l.tokens += elapsed * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
@@ -0,0 +1,77 @@
+package instapaper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEndpointOf(t *testing.T) {
+	cases := map[string]string{
+		"/bookmarks/list":              "/bookmarks/list",
+		"/bookmarks/123/highlights":    "/bookmarks/{id}/highlights",
+		"/bookmarks/123/highlight":     "/bookmarks/{id}/highlight",
+		"/highlights/42/delete":        "/highlights/{id}/delete",
+		"/bookmarks/123/highlights/42": "/bookmarks/{id}/highlights/{id}",
+	}
+	for path, expected := range cases {
+		if endpoint := endpointOf(path); endpoint != expected {
+			t.Errorf("expected %v to map to %v, got %v", path, expected, endpoint)
+		}
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter := NewRateLimiter(100, 1)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("expected err to be nil, got %v", err)
+		}
+	}
+	// the first token is there right away, the other two take 10ms each
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("expected the limiter to smooth the burst, took only %v", elapsed)
+	}
+}
+
+func TestRateLimiterCanceled(t *testing.T) {
+	limiter := NewRateLimiter(0.001, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestRateLimiterSharedByServices(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/folders/list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "[]")
+	})
+	mux.HandleFunc("/bookmarks/list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "{}")
+	})
+	client.RateLimiter = NewRateLimiter(0.001, 3)
+	client.RateLimiter.SetWeight("/bookmarks/list", 2)
+	folders := FolderService{Client: client}
+	bookmarks := BookmarkService{Client: client}
+	if _, err := folders.List(); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if _, err := bookmarks.List(DefaultBookmarkListRequestParams); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := folders.ListContext(ctx)
+	if apiErr, ok := err.(*APIError); !ok || apiErr.ErrorCode != ErrCanceled {
+		t.Errorf("expected the bucket to be drained by both services, got %v", err)
+	}
+}
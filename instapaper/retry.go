@@ -0,0 +1,111 @@
+package instapaper
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how Client.Call retries requests failing with rate limiting (ErrRateLimitExceeded),
+// generic service errors (ErrGeneric), 5xx responses or network errors.
+// see DefaultRetryPolicy for a set of sane defaults
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one. 1 or less disables retrying
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry, it's doubled for every subsequent one
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential backoff. A Retry-After header sent by the server is honoured even if it's longer
+	MaxDelay time.Duration
+	// RetryAll makes non-idempotent endpoints (adding bookmarks, folders and highlights, deletions) retried too.
+	// By default only the endpoints that are safe to repeat are retried
+	RetryAll bool
+	// OnRetry is called before waiting for each retry, useful for logging and metrics
+	OnRetry func(RetryEvent)
+}
+
+// RetryEvent describes a retry that's about to happen
+type RetryEvent struct {
+	Path    string
+	Attempt int // the attempt that just failed, starting from 1
+	Err     error
+	Wait    time.Duration
+}
+
+// DefaultRetryPolicy retries idempotent calls up to 3 times in total, waiting about 1s then 2s in between
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+}
+
+// WithRetryPolicy enables retrying failed calls according to policy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.RetryPolicy = &policy
+	}
+}
+
+var idempotentPaths = map[string]bool{
+	"/bookmarks/list":                 true,
+	"/bookmarks/get_text":             true,
+	"/bookmarks/star":                 true,
+	"/bookmarks/unstar":               true,
+	"/bookmarks/archive":              true,
+	"/bookmarks/unarchive":            true,
+	"/bookmarks/move":                 true,
+	"/bookmarks/update_read_progress": true,
+	"/folders/list":                   true,
+	"/folders/set_order":              true,
+	"/account/verify_credentials":     true,
+}
+
+var highlightListPath = regexp.MustCompile(`^/bookmarks/\d+/highlights$`)
+
+func isIdempotent(path string) bool {
+	return idempotentPaths[path] || highlightListPath.MatchString(path)
+}
+
+// shouldRetry tells whether a call to path failing with err is worth repeating
+func (p *RetryPolicy) shouldRetry(path string, err error) bool {
+	if !p.RetryAll && !isIdempotent(path) {
+		return false
+	}
+	return IsRetryable(err)
+}
+
+// backoff returns how long to wait after the given failed attempt
+func (p *RetryPolicy) backoff(attempt int, err error) time.Duration {
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay > 0 {
+		// "equal jitter": somewhere between half and the full delay
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+		delay = apiErr.RetryAfter
+	}
+	return delay
+}
+
+// parseRetryAfter understands both forms of the Retry-After header: delay in seconds and HTTP date
+func parseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(when); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
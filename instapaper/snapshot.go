@@ -0,0 +1,73 @@
+package instapaper
+
+import (
+	"context"
+	"time"
+)
+
+// Account is a point in time snapshot of everything stored in an Instapaper account, see AccountService.Snapshot
+type Account struct {
+	User       *User
+	Folders    []Folder
+	Bookmarks  []AccountBookmark
+	Highlights []Highlight
+	TakenAt    time.Time
+}
+
+// AccountBookmark is a bookmark along with the IDs of the folders it was listed in.
+// A starred bookmark is listed both in FolderIDStarred and the folder it actually lives in
+type AccountBookmark struct {
+	Bookmark
+	Folders []string
+}
+
+// Snapshot enumerates every folder - built-in and custom - and every bookmark and highlight in them
+func (svc *AccountService) Snapshot() (*Account, error) {
+	return svc.SnapshotContext(context.Background())
+}
+
+// SnapshotContext is like Snapshot but the requests are bound to ctx
+func (svc *AccountService) SnapshotContext(ctx context.Context) (*Account, error) {
+	account := &Account{
+		TakenAt: time.Now(),
+	}
+	user, err := svc.Client.verifyCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account.User = user
+
+	account.Folders, err = svc.Client.Folders.ListAllContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarkIndex := map[int]int{}
+	seenHighlights := map[int]bool{}
+	for _, folder := range account.Folders {
+		folderID := folder.ID.String()
+		it := svc.Client.Bookmarks.ListAll(ctx, folderID)
+		for it.Next() {
+			bookmark := it.Bookmark()
+			if i, ok := bookmarkIndex[bookmark.ID]; ok {
+				account.Bookmarks[i].Folders = append(account.Bookmarks[i].Folders, folderID)
+				continue
+			}
+			bookmarkIndex[bookmark.ID] = len(account.Bookmarks)
+			account.Bookmarks = append(account.Bookmarks, AccountBookmark{
+				Bookmark: bookmark,
+				Folders:  []string{folderID},
+			})
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+		for _, highlight := range it.Highlights() {
+			if !seenHighlights[highlight.ID] {
+				seenHighlights[highlight.ID] = true
+				account.Highlights = append(account.Highlights, highlight)
+			}
+		}
+	}
+	return account, nil
+}
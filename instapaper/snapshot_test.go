@@ -0,0 +1,56 @@
+package instapaper
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	setup()
+	defer teardown()
+	mux.HandleFunc("/account/verify_credentials", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"type":"user","user_id":1,"username":"nope@nope.com","subscription_is_active":"1"}]`)
+	})
+	mux.HandleFunc("/folders/list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"folder_id":100,"title":"Reading","slug":"reading","position":1}]`)
+	})
+	pages := map[string]string{
+		FolderIDUnread:  `{"bookmarks":[{"bookmark_id":1,"starred":"1"}],"highlights":[{"highlight_id":10,"bookmark_id":1}]}`,
+		FolderIDStarred: `{"bookmarks":[{"bookmark_id":1,"starred":"1"}],"highlights":[{"highlight_id":10,"bookmark_id":1}]}`,
+		FolderIDArchive: `{"bookmarks":[{"bookmark_id":2}]}`,
+		"100":           `{"bookmarks":[{"bookmark_id":3}],"highlights":[{"highlight_id":30,"bookmark_id":3}]}`,
+	}
+	mux.HandleFunc("/bookmarks/list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, pages[r.FormValue("folder_id")])
+	})
+	svc := AccountService{
+		Client: client,
+	}
+	account, err := svc.Snapshot()
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if account.User == nil || account.User.ID != 1 {
+		t.Errorf("expected the user to be set, got %v", account.User)
+	}
+	if len(account.Folders) != 4 || !account.Folders[0].BuiltIn || account.Folders[3].BuiltIn {
+		t.Errorf("expected the built-in folders followed by the custom one, got %v", account.Folders)
+	}
+	membership := map[int][]string{}
+	for _, bookmark := range account.Bookmarks {
+		membership[bookmark.ID] = bookmark.Folders
+	}
+	expectedMembership := map[int][]string{
+		1: {FolderIDUnread, FolderIDStarred},
+		2: {FolderIDArchive},
+		3: {"100"},
+	}
+	if !reflect.DeepEqual(membership, expectedMembership) {
+		t.Errorf("expected the folder membership to be %v, got %v", expectedMembership, membership)
+	}
+	if len(account.Highlights) != 2 {
+		t.Errorf("expected 2 distinct highlights, got %v", account.Highlights)
+	}
+}
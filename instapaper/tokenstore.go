@@ -0,0 +1,154 @@
+package instapaper
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gomodule/oauth1/oauth"
+)
+
+// TokenStore persists the OAuth token credentials obtained by Authenticate, so later runs can skip the xAuth exchange
+// and don't need the user's password at all
+type TokenStore interface {
+	// Load returns the stored credentials or nil (and no error) when there are none yet
+	Load() (*oauth.Credentials, error)
+	// Save stores the credentials, replacing any previously stored ones
+	Save(credentials *oauth.Credentials) error
+}
+
+// WithTokenStore makes Authenticate load credentials from store and save freshly obtained ones into it
+func WithTokenStore(store TokenStore) Option {
+	return func(c *Client) {
+		c.TokenStore = store
+	}
+}
+
+// storedToken is the on-disk representation of the credentials
+type storedToken struct {
+	Token  string `json:"oauth_token"`
+	Secret string `json:"oauth_token_secret"`
+}
+
+// FileTokenStore keeps the credentials in a JSON file readable only by the current user
+type FileTokenStore struct {
+	Path string
+}
+
+// Load reads the credentials from the file, a missing file means there are no stored credentials
+func (s *FileTokenStore) Load() (*oauth.Credentials, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var token storedToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, err
+	}
+	if token.Token == "" {
+		return nil, nil
+	}
+	return &oauth.Credentials{Token: token.Token, Secret: token.Secret}, nil
+}
+
+// Save writes the credentials to the file atomically, creating its directory if needed
+func (s *FileTokenStore) Save(credentials *oauth.Credentials) error {
+	data, err := json.MarshalIndent(storedToken{Token: credentials.Token, Secret: credentials.Secret}, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.Path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, ".instapaper-token-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// MemoryTokenStore keeps the credentials in memory only, it's safe for concurrent use
+type MemoryTokenStore struct {
+	mu          sync.Mutex
+	credentials *oauth.Credentials
+}
+
+// Load returns a copy of the stored credentials
+func (s *MemoryTokenStore) Load() (*oauth.Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.credentials == nil {
+		return nil, nil
+	}
+	credentials := *s.credentials
+	return &credentials, nil
+}
+
+// Save stores a copy of the credentials
+func (s *MemoryTokenStore) Save(credentials *oauth.Credentials) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *credentials
+	s.credentials = &stored
+	return nil
+}
+
+// Default environment variable names used by EnvTokenStore
+const (
+	DefaultTokenEnvVar       = "INSTAPAPER_OAUTH_TOKEN"
+	DefaultTokenSecretEnvVar = "INSTAPAPER_OAUTH_TOKEN_SECRET"
+)
+
+// EnvTokenStore reads the credentials from environment variables - handy for CI and containers.
+// Empty variable names fall back to DefaultTokenEnvVar and DefaultTokenSecretEnvVar
+type EnvTokenStore struct {
+	TokenVar  string
+	SecretVar string
+}
+
+func (s *EnvTokenStore) names() (string, string) {
+	tokenVar, secretVar := s.TokenVar, s.SecretVar
+	if tokenVar == "" {
+		tokenVar = DefaultTokenEnvVar
+	}
+	if secretVar == "" {
+		secretVar = DefaultTokenSecretEnvVar
+	}
+	return tokenVar, secretVar
+}
+
+// Load reads the credentials from the environment, an unset token variable means there are no stored credentials
+func (s *EnvTokenStore) Load() (*oauth.Credentials, error) {
+	tokenVar, secretVar := s.names()
+	token := os.Getenv(tokenVar)
+	if token == "" {
+		return nil, nil
+	}
+	return &oauth.Credentials{Token: token, Secret: os.Getenv(secretVar)}, nil
+}
+
+// Save sets the environment variables of the current process, so child processes inherit the credentials
+func (s *EnvTokenStore) Save(credentials *oauth.Credentials) error {
+	tokenVar, secretVar := s.names()
+	if err := os.Setenv(tokenVar, credentials.Token); err != nil {
+		return err
+	}
+	return os.Setenv(secretVar, credentials.Secret)
+}
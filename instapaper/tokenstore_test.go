@@ -0,0 +1,88 @@
+package instapaper
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gomodule/oauth1/oauth"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "instapaper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := &FileTokenStore{Path: filepath.Join(dir, "nested", "token.json")}
+	credentials, err := store.Load()
+	if credentials != nil || err != nil {
+		t.Errorf("expected no credentials and no error for a missing file, got %v, %v", credentials, err)
+	}
+	saved := &oauth.Credentials{Token: "token", Secret: "secret"}
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	info, err := os.Stat(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected the token file to be private, got %v", perm)
+	}
+	credentials, err = store.Load()
+	if err != nil || !reflect.DeepEqual(credentials, saved) {
+		t.Errorf("expected %v, got %v, %v", saved, credentials, err)
+	}
+}
+
+func TestEnvTokenStore(t *testing.T) {
+	store := &EnvTokenStore{TokenVar: "INSTAPAPER_TEST_TOKEN", SecretVar: "INSTAPAPER_TEST_SECRET"}
+	defer os.Unsetenv(store.TokenVar)
+	defer os.Unsetenv(store.SecretVar)
+	if credentials, _ := store.Load(); credentials != nil {
+		t.Errorf("expected no credentials, got %v", credentials)
+	}
+	os.Setenv(store.TokenVar, "token")
+	os.Setenv(store.SecretVar, "secret")
+	credentials, err := store.Load()
+	if err != nil || !reflect.DeepEqual(credentials, &oauth.Credentials{Token: "token", Secret: "secret"}) {
+		t.Errorf("expected the credentials from the environment, got %v, %v", credentials, err)
+	}
+}
+
+func TestAuthenticateWithTokenStore(t *testing.T) {
+	setup()
+	defer teardown()
+	exchanges := 0
+	mux.HandleFunc("/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+		fmt.Fprint(w, "oauth_token=token&oauth_token_secret=secret")
+	})
+	store := &MemoryTokenStore{}
+	client.Credentials = nil
+	client.TokenStore = store
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("expected Authenticate() to succeed, got %v", err)
+	}
+	saved, _ := store.Load()
+	if !reflect.DeepEqual(saved, &oauth.Credentials{Token: "token", Secret: "secret"}) {
+		t.Errorf("expected the credentials to be saved, got %v", saved)
+	}
+
+	client.Credentials = nil
+	client.Password = ""
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("expected Authenticate() to succeed, got %v", err)
+	}
+	if exchanges != 1 {
+		t.Errorf("expected the stored credentials to be reused, got %d xAuth exchanges", exchanges)
+	}
+	if !reflect.DeepEqual(client.Credentials, saved) {
+		t.Errorf("expected the client's credentials to be %v, got %v", saved, client.Credentials)
+	}
+}
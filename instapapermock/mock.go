@@ -0,0 +1,64 @@
+// Package instapapermock provides in-memory mocks of the instapaper service interfaces - BookmarkAPI, FolderAPI,
+// HighlightAPI and AccountAPI - recording every call, for unit testing code that depends on them.
+//
+// Set the Func field of a method to control what it returns:
+//
+//	bookmarks := &instapapermock.BookmarkAPI{
+//		StarContextFunc: func(ctx context.Context, bookmarkID int) error {
+//			return nil
+//		},
+//	}
+//	... code under test calling bookmarks.Star(1) ...
+//	calls := bookmarks.CallsTo("Star")
+//
+// The mocks are generated from instapaper/api.go, run go generate after changing the interfaces.
+package instapapermock
+
+//go:generate go run ./internal/gen
+
+import "sync"
+
+// Call is a recorded method call
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// recorder keeps the calls of a mock, it's safe for concurrent use
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns every call made so far, in order
+func (r *recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsTo returns the calls of a single method, in order
+func (r *recorder) CallsTo(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var calls []Call
+	for _, c := range r.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the calls made so far
+func (r *recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
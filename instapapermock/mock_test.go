@@ -0,0 +1,145 @@
+package instapapermock
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+func TestBookmarkAPIDelegatesToContext(t *testing.T) {
+	m := &BookmarkAPI{
+		StarContextFunc: func(ctx context.Context, bookmarkID int) error {
+			if bookmarkID != 42 {
+				t.Errorf("expected bookmark 42, got %d", bookmarkID)
+			}
+			return errors.New("boom")
+		},
+	}
+	if err := m.Star(42); err == nil || err.Error() != "boom" {
+		t.Errorf("expected the error of StarContextFunc, got %v", err)
+	}
+	calls := m.Calls()
+	if len(calls) != 2 || calls[0].Method != "Star" || calls[1].Method != "StarContext" {
+		t.Fatalf("expected Star then StarContext, got %+v", calls)
+	}
+	if !reflect.DeepEqual(calls[0].Args, []interface{}{42}) {
+		t.Errorf("expected args [42], got %v", calls[0].Args)
+	}
+	m.Reset()
+	if len(m.Calls()) != 0 {
+		t.Errorf("expected no calls after Reset, got %d", len(m.Calls()))
+	}
+}
+
+func TestBookmarkAPIFuncShortCircuits(t *testing.T) {
+	var got []int
+	m := &BookmarkAPI{
+		ArchiveFunc: func(bookmarkID int) error {
+			got = append(got, bookmarkID)
+			return nil
+		},
+	}
+	if err := m.Archive(1); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("expected ArchiveFunc to be called with 1, got %v", got)
+	}
+	if n := len(m.CallsTo("ArchiveContext")); n != 0 {
+		t.Errorf("expected ArchiveContext not to be called, got %d calls", n)
+	}
+}
+
+func TestBookmarkAPIZeroValues(t *testing.T) {
+	m := &BookmarkAPI{}
+	text, err := m.GetText(1)
+	if text != "" || err != nil {
+		t.Errorf("expected zero values, got %q, %v", text, err)
+	}
+	bookmark, err := m.Add(instapaper.BookmarkAddRequestParams{URL: "https://example.com"})
+	if bookmark != nil || err != nil {
+		t.Errorf("expected zero values, got %v, %v", bookmark, err)
+	}
+}
+
+func TestBookmarkAPIListAll(t *testing.T) {
+	m := &BookmarkAPI{
+		ListContextFunc: func(ctx context.Context, p instapaper.BookmarkListRequestParams) (*instapaper.BookmarkListResponse, error) {
+			if len(p.Skip) > 0 {
+				return &instapaper.BookmarkListResponse{}, nil
+			}
+			return &instapaper.BookmarkListResponse{Bookmarks: []instapaper.Bookmark{{ID: 1}, {ID: 2}}}, nil
+		},
+	}
+	var ids []int
+	it := m.ListAll(context.Background(), instapaper.FolderIDArchive)
+	for it.Next() {
+		ids = append(ids, it.Bookmark().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("expected bookmarks [1 2], got %v", ids)
+	}
+	calls := m.CallsTo("ListContext")
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 ListContext call, got %d", len(calls))
+	}
+	p := calls[0].Args[1].(instapaper.BookmarkListRequestParams)
+	if p.Folder != instapaper.FolderIDArchive {
+		t.Errorf("expected folder %s, got %s", instapaper.FolderIDArchive, p.Folder)
+	}
+}
+
+func TestFolderAndHighlightAPI(t *testing.T) {
+	folders := &FolderAPI{
+		AddContextFunc: func(ctx context.Context, title string) (*instapaper.Folder, error) {
+			return &instapaper.Folder{Title: title}, nil
+		},
+	}
+	folder, err := folders.Add("Reading")
+	if err != nil || folder.Title != "Reading" {
+		t.Errorf("expected folder Reading, got %v, %v", folder, err)
+	}
+
+	highlights := &HighlightAPI{
+		DeleteFunc: func(highlightID int) error {
+			return nil
+		},
+	}
+	if err := highlights.Delete(3); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(highlights.Calls(), []Call{{Method: "Delete", Args: []interface{}{3}}}) {
+		t.Errorf("expected a single Delete call, got %+v", highlights.Calls())
+	}
+}
+
+func TestBookmarkAPIListAllDefault(t *testing.T) {
+	it := (&BookmarkAPI{}).ListAll(context.Background(), instapaper.FolderIDUnread)
+	if it.Next() {
+		t.Errorf("expected no bookmarks, got %v", it.Bookmark())
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestAccountAPI(t *testing.T) {
+	m := &AccountAPI{
+		VerifyCredentialsContextFunc: func(ctx context.Context) (*instapaper.User, error) {
+			return &instapaper.User{Username: "gopher"}, nil
+		},
+	}
+	user, err := m.VerifyCredentials()
+	if err != nil || user.Username != "gopher" {
+		t.Errorf("expected user gopher, got %v, %v", user, err)
+	}
+	if account, err := m.Snapshot(); account != nil || err != nil {
+		t.Errorf("expected zero values, got %v, %v", account, err)
+	}
+}
@@ -0,0 +1,459 @@
+// Code generated by instapapermock/internal/gen from instapaper/api.go. DO NOT EDIT.
+
+package instapapermock
+
+import (
+	"context"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// BookmarkAPI is a mock of instapaper.BookmarkAPI recording every call. A method calls its Func field if set.
+// Otherwise a method with a Context variant calls that variant with context.Background(), like the real services
+// do, and any other method returns zero values
+type BookmarkAPI struct {
+	recorder
+
+	ListFunc                      func(p instapaper.BookmarkListRequestParams) (*instapaper.BookmarkListResponse, error)
+	ListContextFunc               func(ctx context.Context, p instapaper.BookmarkListRequestParams) (*instapaper.BookmarkListResponse, error)
+	ListAllFunc                   func(ctx context.Context, folder string) *instapaper.BookmarkIterator
+	GetTextFunc                   func(bookmarkID int) (string, error)
+	GetTextContextFunc            func(ctx context.Context, bookmarkID int) (string, error)
+	StarFunc                      func(bookmarkID int) error
+	StarContextFunc               func(ctx context.Context, bookmarkID int) error
+	UnStarFunc                    func(bookmarkID int) error
+	UnStarContextFunc             func(ctx context.Context, bookmarkID int) error
+	ArchiveFunc                   func(bookmarkID int) error
+	ArchiveContextFunc            func(ctx context.Context, bookmarkID int) error
+	UnArchiveFunc                 func(bookmarkID int) error
+	UnArchiveContextFunc          func(ctx context.Context, bookmarkID int) error
+	DeletePermanentlyFunc         func(bookmarkID int) error
+	DeletePermanentlyContextFunc  func(ctx context.Context, bookmarkID int) error
+	MoveFunc                      func(bookmarkID int, folderID string) error
+	MoveContextFunc               func(ctx context.Context, bookmarkID int, folderID string) error
+	UpdateReadProgressFunc        func(bookmarkID int, progress float32, when int64) error
+	UpdateReadProgressContextFunc func(ctx context.Context, bookmarkID int, progress float32, when int64) error
+	AddFunc                       func(p instapaper.BookmarkAddRequestParams) (*instapaper.Bookmark, error)
+	AddContextFunc                func(ctx context.Context, p instapaper.BookmarkAddRequestParams) (*instapaper.Bookmark, error)
+}
+
+var _ instapaper.BookmarkAPI = (*BookmarkAPI)(nil)
+
+// List records the call and calls ListFunc
+func (m *BookmarkAPI) List(p instapaper.BookmarkListRequestParams) (*instapaper.BookmarkListResponse, error) {
+	m.record("List", p)
+	if m.ListFunc != nil {
+		return m.ListFunc(p)
+	}
+	return m.ListContext(context.Background(), p)
+}
+
+// ListContext records the call and calls ListContextFunc
+func (m *BookmarkAPI) ListContext(ctx context.Context, p instapaper.BookmarkListRequestParams) (*instapaper.BookmarkListResponse, error) {
+	m.record("ListContext", ctx, p)
+	if m.ListContextFunc != nil {
+		return m.ListContextFunc(ctx, p)
+	}
+	return &instapaper.BookmarkListResponse{}, nil
+}
+
+// ListAll records the call and calls ListAllFunc
+func (m *BookmarkAPI) ListAll(ctx context.Context, folder string) *instapaper.BookmarkIterator {
+	m.record("ListAll", ctx, folder)
+	if m.ListAllFunc != nil {
+		return m.ListAllFunc(ctx, folder)
+	}
+	return instapaper.IterateBookmarks(ctx, m, folder)
+}
+
+// GetText records the call and calls GetTextFunc
+func (m *BookmarkAPI) GetText(bookmarkID int) (string, error) {
+	m.record("GetText", bookmarkID)
+	if m.GetTextFunc != nil {
+		return m.GetTextFunc(bookmarkID)
+	}
+	return m.GetTextContext(context.Background(), bookmarkID)
+}
+
+// GetTextContext records the call and calls GetTextContextFunc
+func (m *BookmarkAPI) GetTextContext(ctx context.Context, bookmarkID int) (string, error) {
+	m.record("GetTextContext", ctx, bookmarkID)
+	if m.GetTextContextFunc != nil {
+		return m.GetTextContextFunc(ctx, bookmarkID)
+	}
+	return "", nil
+}
+
+// Star records the call and calls StarFunc
+func (m *BookmarkAPI) Star(bookmarkID int) error {
+	m.record("Star", bookmarkID)
+	if m.StarFunc != nil {
+		return m.StarFunc(bookmarkID)
+	}
+	return m.StarContext(context.Background(), bookmarkID)
+}
+
+// StarContext records the call and calls StarContextFunc
+func (m *BookmarkAPI) StarContext(ctx context.Context, bookmarkID int) error {
+	m.record("StarContext", ctx, bookmarkID)
+	if m.StarContextFunc != nil {
+		return m.StarContextFunc(ctx, bookmarkID)
+	}
+	return nil
+}
+
+// UnStar records the call and calls UnStarFunc
+func (m *BookmarkAPI) UnStar(bookmarkID int) error {
+	m.record("UnStar", bookmarkID)
+	if m.UnStarFunc != nil {
+		return m.UnStarFunc(bookmarkID)
+	}
+	return m.UnStarContext(context.Background(), bookmarkID)
+}
+
+// UnStarContext records the call and calls UnStarContextFunc
+func (m *BookmarkAPI) UnStarContext(ctx context.Context, bookmarkID int) error {
+	m.record("UnStarContext", ctx, bookmarkID)
+	if m.UnStarContextFunc != nil {
+		return m.UnStarContextFunc(ctx, bookmarkID)
+	}
+	return nil
+}
+
+// Archive records the call and calls ArchiveFunc
+func (m *BookmarkAPI) Archive(bookmarkID int) error {
+	m.record("Archive", bookmarkID)
+	if m.ArchiveFunc != nil {
+		return m.ArchiveFunc(bookmarkID)
+	}
+	return m.ArchiveContext(context.Background(), bookmarkID)
+}
+
+// ArchiveContext records the call and calls ArchiveContextFunc
+func (m *BookmarkAPI) ArchiveContext(ctx context.Context, bookmarkID int) error {
+	m.record("ArchiveContext", ctx, bookmarkID)
+	if m.ArchiveContextFunc != nil {
+		return m.ArchiveContextFunc(ctx, bookmarkID)
+	}
+	return nil
+}
+
+// UnArchive records the call and calls UnArchiveFunc
+func (m *BookmarkAPI) UnArchive(bookmarkID int) error {
+	m.record("UnArchive", bookmarkID)
+	if m.UnArchiveFunc != nil {
+		return m.UnArchiveFunc(bookmarkID)
+	}
+	return m.UnArchiveContext(context.Background(), bookmarkID)
+}
+
+// UnArchiveContext records the call and calls UnArchiveContextFunc
+func (m *BookmarkAPI) UnArchiveContext(ctx context.Context, bookmarkID int) error {
+	m.record("UnArchiveContext", ctx, bookmarkID)
+	if m.UnArchiveContextFunc != nil {
+		return m.UnArchiveContextFunc(ctx, bookmarkID)
+	}
+	return nil
+}
+
+// DeletePermanently records the call and calls DeletePermanentlyFunc
+func (m *BookmarkAPI) DeletePermanently(bookmarkID int) error {
+	m.record("DeletePermanently", bookmarkID)
+	if m.DeletePermanentlyFunc != nil {
+		return m.DeletePermanentlyFunc(bookmarkID)
+	}
+	return m.DeletePermanentlyContext(context.Background(), bookmarkID)
+}
+
+// DeletePermanentlyContext records the call and calls DeletePermanentlyContextFunc
+func (m *BookmarkAPI) DeletePermanentlyContext(ctx context.Context, bookmarkID int) error {
+	m.record("DeletePermanentlyContext", ctx, bookmarkID)
+	if m.DeletePermanentlyContextFunc != nil {
+		return m.DeletePermanentlyContextFunc(ctx, bookmarkID)
+	}
+	return nil
+}
+
+// Move records the call and calls MoveFunc
+func (m *BookmarkAPI) Move(bookmarkID int, folderID string) error {
+	m.record("Move", bookmarkID, folderID)
+	if m.MoveFunc != nil {
+		return m.MoveFunc(bookmarkID, folderID)
+	}
+	return m.MoveContext(context.Background(), bookmarkID, folderID)
+}
+
+// MoveContext records the call and calls MoveContextFunc
+func (m *BookmarkAPI) MoveContext(ctx context.Context, bookmarkID int, folderID string) error {
+	m.record("MoveContext", ctx, bookmarkID, folderID)
+	if m.MoveContextFunc != nil {
+		return m.MoveContextFunc(ctx, bookmarkID, folderID)
+	}
+	return nil
+}
+
+// UpdateReadProgress records the call and calls UpdateReadProgressFunc
+func (m *BookmarkAPI) UpdateReadProgress(bookmarkID int, progress float32, when int64) error {
+	m.record("UpdateReadProgress", bookmarkID, progress, when)
+	if m.UpdateReadProgressFunc != nil {
+		return m.UpdateReadProgressFunc(bookmarkID, progress, when)
+	}
+	return m.UpdateReadProgressContext(context.Background(), bookmarkID, progress, when)
+}
+
+// UpdateReadProgressContext records the call and calls UpdateReadProgressContextFunc
+func (m *BookmarkAPI) UpdateReadProgressContext(ctx context.Context, bookmarkID int, progress float32, when int64) error {
+	m.record("UpdateReadProgressContext", ctx, bookmarkID, progress, when)
+	if m.UpdateReadProgressContextFunc != nil {
+		return m.UpdateReadProgressContextFunc(ctx, bookmarkID, progress, when)
+	}
+	return nil
+}
+
+// Add records the call and calls AddFunc
+func (m *BookmarkAPI) Add(p instapaper.BookmarkAddRequestParams) (*instapaper.Bookmark, error) {
+	m.record("Add", p)
+	if m.AddFunc != nil {
+		return m.AddFunc(p)
+	}
+	return m.AddContext(context.Background(), p)
+}
+
+// AddContext records the call and calls AddContextFunc
+func (m *BookmarkAPI) AddContext(ctx context.Context, p instapaper.BookmarkAddRequestParams) (*instapaper.Bookmark, error) {
+	m.record("AddContext", ctx, p)
+	if m.AddContextFunc != nil {
+		return m.AddContextFunc(ctx, p)
+	}
+	return nil, nil
+}
+
+// FolderAPI is a mock of instapaper.FolderAPI recording every call. A method calls its Func field if set.
+// Otherwise a method with a Context variant calls that variant with context.Background(), like the real services
+// do, and any other method returns zero values
+type FolderAPI struct {
+	recorder
+
+	ListFunc            func() ([]instapaper.Folder, error)
+	ListContextFunc     func(ctx context.Context) ([]instapaper.Folder, error)
+	ListAllFunc         func() ([]instapaper.Folder, error)
+	ListAllContextFunc  func(ctx context.Context) ([]instapaper.Folder, error)
+	AddFunc             func(title string) (*instapaper.Folder, error)
+	AddContextFunc      func(ctx context.Context, title string) (*instapaper.Folder, error)
+	DeleteFunc          func(folderID string) error
+	DeleteContextFunc   func(ctx context.Context, folderID string) error
+	SetOrderFunc        func(folderOrderlist string) ([]instapaper.Folder, error)
+	SetOrderContextFunc func(ctx context.Context, folderOrderlist string) ([]instapaper.Folder, error)
+}
+
+var _ instapaper.FolderAPI = (*FolderAPI)(nil)
+
+// List records the call and calls ListFunc
+func (m *FolderAPI) List() ([]instapaper.Folder, error) {
+	m.record("List")
+	if m.ListFunc != nil {
+		return m.ListFunc()
+	}
+	return m.ListContext(context.Background())
+}
+
+// ListContext records the call and calls ListContextFunc
+func (m *FolderAPI) ListContext(ctx context.Context) ([]instapaper.Folder, error) {
+	m.record("ListContext", ctx)
+	if m.ListContextFunc != nil {
+		return m.ListContextFunc(ctx)
+	}
+	return nil, nil
+}
+
+// ListAll records the call and calls ListAllFunc
+func (m *FolderAPI) ListAll() ([]instapaper.Folder, error) {
+	m.record("ListAll")
+	if m.ListAllFunc != nil {
+		return m.ListAllFunc()
+	}
+	return m.ListAllContext(context.Background())
+}
+
+// ListAllContext records the call and calls ListAllContextFunc
+func (m *FolderAPI) ListAllContext(ctx context.Context) ([]instapaper.Folder, error) {
+	m.record("ListAllContext", ctx)
+	if m.ListAllContextFunc != nil {
+		return m.ListAllContextFunc(ctx)
+	}
+	return nil, nil
+}
+
+// Add records the call and calls AddFunc
+func (m *FolderAPI) Add(title string) (*instapaper.Folder, error) {
+	m.record("Add", title)
+	if m.AddFunc != nil {
+		return m.AddFunc(title)
+	}
+	return m.AddContext(context.Background(), title)
+}
+
+// AddContext records the call and calls AddContextFunc
+func (m *FolderAPI) AddContext(ctx context.Context, title string) (*instapaper.Folder, error) {
+	m.record("AddContext", ctx, title)
+	if m.AddContextFunc != nil {
+		return m.AddContextFunc(ctx, title)
+	}
+	return nil, nil
+}
+
+// Delete records the call and calls DeleteFunc
+func (m *FolderAPI) Delete(folderID string) error {
+	m.record("Delete", folderID)
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(folderID)
+	}
+	return m.DeleteContext(context.Background(), folderID)
+}
+
+// DeleteContext records the call and calls DeleteContextFunc
+func (m *FolderAPI) DeleteContext(ctx context.Context, folderID string) error {
+	m.record("DeleteContext", ctx, folderID)
+	if m.DeleteContextFunc != nil {
+		return m.DeleteContextFunc(ctx, folderID)
+	}
+	return nil
+}
+
+// SetOrder records the call and calls SetOrderFunc
+func (m *FolderAPI) SetOrder(folderOrderlist string) ([]instapaper.Folder, error) {
+	m.record("SetOrder", folderOrderlist)
+	if m.SetOrderFunc != nil {
+		return m.SetOrderFunc(folderOrderlist)
+	}
+	return m.SetOrderContext(context.Background(), folderOrderlist)
+}
+
+// SetOrderContext records the call and calls SetOrderContextFunc
+func (m *FolderAPI) SetOrderContext(ctx context.Context, folderOrderlist string) ([]instapaper.Folder, error) {
+	m.record("SetOrderContext", ctx, folderOrderlist)
+	if m.SetOrderContextFunc != nil {
+		return m.SetOrderContextFunc(ctx, folderOrderlist)
+	}
+	return nil, nil
+}
+
+// HighlightAPI is a mock of instapaper.HighlightAPI recording every call. A method calls its Func field if set.
+// Otherwise a method with a Context variant calls that variant with context.Background(), like the real services
+// do, and any other method returns zero values
+type HighlightAPI struct {
+	recorder
+
+	ListFunc          func(bookmarkID int) ([]instapaper.Highlight, error)
+	ListContextFunc   func(ctx context.Context, bookmarkID int) ([]instapaper.Highlight, error)
+	AddFunc           func(bookmarkID int, text string, position int) (*instapaper.Highlight, error)
+	AddContextFunc    func(ctx context.Context, bookmarkID int, text string, position int) (*instapaper.Highlight, error)
+	DeleteFunc        func(highlightID int) error
+	DeleteContextFunc func(ctx context.Context, highlightID int) error
+}
+
+var _ instapaper.HighlightAPI = (*HighlightAPI)(nil)
+
+// List records the call and calls ListFunc
+func (m *HighlightAPI) List(bookmarkID int) ([]instapaper.Highlight, error) {
+	m.record("List", bookmarkID)
+	if m.ListFunc != nil {
+		return m.ListFunc(bookmarkID)
+	}
+	return m.ListContext(context.Background(), bookmarkID)
+}
+
+// ListContext records the call and calls ListContextFunc
+func (m *HighlightAPI) ListContext(ctx context.Context, bookmarkID int) ([]instapaper.Highlight, error) {
+	m.record("ListContext", ctx, bookmarkID)
+	if m.ListContextFunc != nil {
+		return m.ListContextFunc(ctx, bookmarkID)
+	}
+	return nil, nil
+}
+
+// Add records the call and calls AddFunc
+func (m *HighlightAPI) Add(bookmarkID int, text string, position int) (*instapaper.Highlight, error) {
+	m.record("Add", bookmarkID, text, position)
+	if m.AddFunc != nil {
+		return m.AddFunc(bookmarkID, text, position)
+	}
+	return m.AddContext(context.Background(), bookmarkID, text, position)
+}
+
+// AddContext records the call and calls AddContextFunc
+func (m *HighlightAPI) AddContext(ctx context.Context, bookmarkID int, text string, position int) (*instapaper.Highlight, error) {
+	m.record("AddContext", ctx, bookmarkID, text, position)
+	if m.AddContextFunc != nil {
+		return m.AddContextFunc(ctx, bookmarkID, text, position)
+	}
+	return nil, nil
+}
+
+// Delete records the call and calls DeleteFunc
+func (m *HighlightAPI) Delete(highlightID int) error {
+	m.record("Delete", highlightID)
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(highlightID)
+	}
+	return m.DeleteContext(context.Background(), highlightID)
+}
+
+// DeleteContext records the call and calls DeleteContextFunc
+func (m *HighlightAPI) DeleteContext(ctx context.Context, highlightID int) error {
+	m.record("DeleteContext", ctx, highlightID)
+	if m.DeleteContextFunc != nil {
+		return m.DeleteContextFunc(ctx, highlightID)
+	}
+	return nil
+}
+
+// AccountAPI is a mock of instapaper.AccountAPI recording every call. A method calls its Func field if set.
+// Otherwise a method with a Context variant calls that variant with context.Background(), like the real services
+// do, and any other method returns zero values
+type AccountAPI struct {
+	recorder
+
+	VerifyCredentialsFunc        func() (*instapaper.User, error)
+	VerifyCredentialsContextFunc func(ctx context.Context) (*instapaper.User, error)
+	SnapshotFunc                 func() (*instapaper.Account, error)
+	SnapshotContextFunc          func(ctx context.Context) (*instapaper.Account, error)
+}
+
+var _ instapaper.AccountAPI = (*AccountAPI)(nil)
+
+// VerifyCredentials records the call and calls VerifyCredentialsFunc
+func (m *AccountAPI) VerifyCredentials() (*instapaper.User, error) {
+	m.record("VerifyCredentials")
+	if m.VerifyCredentialsFunc != nil {
+		return m.VerifyCredentialsFunc()
+	}
+	return m.VerifyCredentialsContext(context.Background())
+}
+
+// VerifyCredentialsContext records the call and calls VerifyCredentialsContextFunc
+func (m *AccountAPI) VerifyCredentialsContext(ctx context.Context) (*instapaper.User, error) {
+	m.record("VerifyCredentialsContext", ctx)
+	if m.VerifyCredentialsContextFunc != nil {
+		return m.VerifyCredentialsContextFunc(ctx)
+	}
+	return nil, nil
+}
+
+// Snapshot records the call and calls SnapshotFunc
+func (m *AccountAPI) Snapshot() (*instapaper.Account, error) {
+	m.record("Snapshot")
+	if m.SnapshotFunc != nil {
+		return m.SnapshotFunc()
+	}
+	return m.SnapshotContext(context.Background())
+}
+
+// SnapshotContext records the call and calls SnapshotContextFunc
+func (m *AccountAPI) SnapshotContext(ctx context.Context) (*instapaper.Account, error) {
+	m.record("SnapshotContext", ctx)
+	if m.SnapshotContextFunc != nil {
+		return m.SnapshotContextFunc(ctx)
+	}
+	return nil, nil
+}
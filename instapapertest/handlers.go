@@ -0,0 +1,469 @@
+package instapapertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// The wire types are shaped like the objects of the real API, type field included
+
+type wireError struct {
+	Type      string `json:"type"`
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+type wireUser struct {
+	Type                 string `json:"type"`
+	UserID               int    `json:"user_id"`
+	Username             string `json:"username"`
+	SubscriptionIsActive string `json:"subscription_is_active"`
+}
+
+type wireBookmark struct {
+	Type              string  `json:"type"`
+	BookmarkID        int     `json:"bookmark_id"`
+	URL               string  `json:"url"`
+	Title             string  `json:"title"`
+	Description       string  `json:"description"`
+	Time              int64   `json:"time"`
+	Starred           string  `json:"starred"`
+	PrivateSource     string  `json:"private_source"`
+	Hash              string  `json:"hash"`
+	Progress          float32 `json:"progress"`
+	ProgressTimestamp int64   `json:"progress_timestamp"`
+}
+
+type wireFolder struct {
+	Type         string `json:"type"`
+	FolderID     int    `json:"folder_id"`
+	Title        string `json:"title"`
+	Slug         string `json:"slug"`
+	DisplayTitle string `json:"display_title"`
+	SyncToMobile int    `json:"sync_to_mobile"`
+	Position     int    `json:"position"`
+}
+
+type wireHighlight struct {
+	Type        string `json:"type"`
+	HighlightID int    `json:"highlight_id"`
+	BookmarkID  int    `json:"bookmark_id"`
+	Text        string `json:"text"`
+	Note        string `json:"note,omitempty"`
+	Time        int64  `json:"time"`
+	Position    int    `json:"position"`
+}
+
+type wireList struct {
+	User       wireUser        `json:"user"`
+	Bookmarks  []wireBookmark  `json:"bookmarks"`
+	Highlights []wireHighlight `json:"highlights"`
+	DeleteIDs  []int           `json:"delete_ids"`
+}
+
+func (s *Server) wireUser() wireUser {
+	return wireUser{
+		Type:                 "user",
+		UserID:               s.user.ID,
+		Username:             s.user.Username,
+		SubscriptionIsActive: s.user.SubscriptionIsActive,
+	}
+}
+
+func toWireBookmark(b *bookmark) wireBookmark {
+	starred := b.Starred
+	if starred == "" {
+		starred = "0"
+	}
+	return wireBookmark{
+		Type:              "bookmark",
+		BookmarkID:        b.ID,
+		URL:               b.URL,
+		Title:             b.Title,
+		Description:       b.Description,
+		Time:              int64(b.Time),
+		Starred:           starred,
+		PrivateSource:     b.PrivateSource,
+		Hash:              b.Hash,
+		Progress:          b.Progress,
+		ProgressTimestamp: b.ProgressTimestamp,
+	}
+}
+
+func toWireHighlight(h instapaper.Highlight) wireHighlight {
+	t, _ := h.Time.Int64()
+	return wireHighlight{
+		Type:        "highlight",
+		HighlightID: h.ID,
+		BookmarkID:  h.BookmarkID,
+		Text:        h.Text,
+		Note:        h.Note,
+		Time:        t,
+		Position:    h.Position,
+	}
+}
+
+func (s *Server) wireFolders() []wireFolder {
+	folders := []wireFolder{}
+	for _, f := range s.folders {
+		folders = append(folders, toWireFolder(f))
+	}
+	return folders
+}
+
+func toWireFolder(f instapaper.Folder) wireFolder {
+	id, _ := f.ID.Int64()
+	position, _ := f.Position.Int64()
+	return wireFolder{
+		Type:         "folder",
+		FolderID:     int(id),
+		Title:        f.Title,
+		Slug:         f.Slug,
+		DisplayTitle: f.DisplayTitle,
+		SyncToMobile: f.SyncToMobile,
+		Position:     int(position),
+	}
+}
+
+// bookmarkParam returns the bookmark named by the bookmark_id parameter
+func (s *Server) bookmarkParam(form url.Values) (*bookmark, *apiError) {
+	id, err := strconv.Atoi(form.Get("bookmark_id"))
+	if err != nil {
+		return nil, fail(instapaper.ErrInvalidBookmarkID)
+	}
+	return s.bookmarkByID(id)
+}
+
+func (s *Server) bookmarkByID(id int) (*bookmark, *apiError) {
+	b, ok := s.bookmarks[id]
+	if !ok {
+		return nil, fail(instapaper.ErrInvalidBookmarkID)
+	}
+	return b, nil
+}
+
+// list implements bookmarks/list, including the "have" and "highlights" parameters
+func (s *Server) list(form url.Values) (interface{}, *apiError) {
+	folder := form.Get("folder_id")
+	if folder == "" {
+		folder = instapaper.FolderIDUnread
+	}
+	if folder != instapaper.FolderIDUnread && folder != instapaper.FolderIDStarred && folder != instapaper.FolderIDArchive &&
+		s.folderIndex(folder) < 0 {
+		return nil, fail(instapaper.ErrInvalidFolderID)
+	}
+	limit := 25
+	if l, err := strconv.Atoi(form.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	// have is a comma separated list of id or id:hash or id:hash:progress:progress_timestamp
+	have := map[int]string{}
+	for _, item := range strings.Split(form.Get("have"), ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		id, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+		have[id] = ""
+		if len(parts) > 1 {
+			have[id] = parts[1]
+		}
+		if len(parts) == 4 {
+			s.syncProgress(id, parts[2], parts[3])
+		}
+	}
+	skipHighlights := map[int]bool{}
+	for _, id := range strings.Split(form.Get("highlights"), "-") {
+		if n, err := strconv.Atoi(id); err == nil {
+			skipHighlights[n] = true
+		}
+	}
+
+	res := wireList{
+		User:       s.wireUser(),
+		Bookmarks:  []wireBookmark{},
+		Highlights: []wireHighlight{},
+		DeleteIDs:  []int{},
+	}
+	inFolder := map[int]bool{}
+	for _, b := range s.inFolder(folder) {
+		inFolder[b.ID] = true
+		if hash, ok := have[b.ID]; ok && (hash == "" || hash == b.Hash) {
+			continue
+		}
+		if len(res.Bookmarks) == limit {
+			continue
+		}
+		res.Bookmarks = append(res.Bookmarks, toWireBookmark(b))
+		for _, h := range s.highlightsOf(b.ID) {
+			if !skipHighlights[h.ID] {
+				res.Highlights = append(res.Highlights, toWireHighlight(h))
+			}
+		}
+	}
+	for id := range have {
+		if !inFolder[id] {
+			res.DeleteIDs = append(res.DeleteIDs, id)
+		}
+	}
+	return res, nil
+}
+
+// syncProgress applies the reading progress a client reports through "have", if it's newer than the stored one
+func (s *Server) syncProgress(id int, progress, timestamp string) {
+	b, ok := s.bookmarks[id]
+	if !ok {
+		return
+	}
+	p, err := strconv.ParseFloat(progress, 32)
+	if err != nil {
+		return
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || ts <= b.ProgressTimestamp {
+		return
+	}
+	b.Progress = float32(p)
+	b.ProgressTimestamp = ts
+	b.rehash()
+}
+
+// getText writes the text view of a bookmark, the only endpoint answering with HTML
+func (s *Server) getText(w http.ResponseWriter, form url.Values) {
+	b, apiErr := s.bookmarkParam(form)
+	if apiErr != nil {
+		writeError(w, apiErr.status, apiErr.code, "")
+		return
+	}
+	text := b.text
+	if text == "" {
+		title := html.EscapeString(b.Title)
+		text = fmt.Sprintf("<!DOCTYPE html>\n<html>\n<head><title>%s</title></head>\n<body>\n<div id=\"story\">\n<h1>%s</h1>\n<p>%s</p>\n</div>\n</body>\n</html>\n",
+			title, title, html.EscapeString(b.Description))
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, text)
+}
+
+// add implements bookmarks/add. Adding a URL already in the account updates that bookmark, like the real API does
+func (s *Server) add(form url.Values) (interface{}, *apiError) {
+	rawURL := strings.TrimSpace(form.Get("url"))
+	content := form.Get("content")
+	privateSource := form.Get("is_private_from_source")
+	if privateSource != "" && content == "" {
+		return nil, fail(instapaper.ErrSuppliedContentRequired)
+	}
+	if privateSource == "" {
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fail(instapaper.ErrInvalidURL)
+		}
+	}
+	folder := instapaper.FolderIDUnread
+	if id := form.Get("folder_id"); id != "" {
+		if id == instapaper.FolderIDUnread || id == instapaper.FolderIDStarred || id == instapaper.FolderIDArchive {
+			return nil, fail(instapaper.ErrCannotAddBookmarkToFolder)
+		}
+		if s.folderIndex(id) < 0 {
+			return nil, fail(instapaper.ErrInvalidFolderID)
+		}
+		folder = id
+	}
+
+	var b *bookmark
+	if privateSource == "" {
+		for _, existing := range s.bookmarks {
+			if existing.URL == rawURL {
+				b = existing
+				break
+			}
+		}
+	}
+	if b == nil {
+		b = &bookmark{Bookmark: instapaper.Bookmark{ID: s.id(), URL: rawURL, Starred: "0"}}
+		s.bookmarks[b.ID] = b
+	}
+	b.folder = folder
+	b.Time = float32(s.now().Unix())
+	b.PrivateSource = privateSource
+	if title := form.Get("title"); title != "" {
+		b.Title = title
+	} else if b.Title == "" {
+		b.Title = rawURL
+	}
+	if description := form.Get("description"); description != "" {
+		b.Description = description
+	}
+	if content != "" {
+		b.text = content
+	}
+	b.rehash()
+	return []wireBookmark{toWireBookmark(b)}, nil
+}
+
+func (s *Server) deleteBookmark(form url.Values) (interface{}, *apiError) {
+	b, err := s.bookmarkParam(form)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.bookmarks, b.ID)
+	for id, h := range s.highlights {
+		if h.BookmarkID == b.ID {
+			delete(s.highlights, id)
+		}
+	}
+	return []interface{}{}, nil
+}
+
+// mutate implements star, unstar, archive and unarchive
+func (s *Server) mutate(action string, form url.Values) (interface{}, *apiError) {
+	b, err := s.bookmarkParam(form)
+	if err != nil {
+		return nil, err
+	}
+	switch action {
+	case "star":
+		b.Starred = "1"
+	case "unstar":
+		b.Starred = "0"
+	case "archive":
+		b.folder = instapaper.FolderIDArchive
+	case "unarchive":
+		b.folder = instapaper.FolderIDUnread
+	}
+	b.rehash()
+	return []wireBookmark{toWireBookmark(b)}, nil
+}
+
+func (s *Server) move(form url.Values) (interface{}, *apiError) {
+	b, err := s.bookmarkParam(form)
+	if err != nil {
+		return nil, err
+	}
+	folder := form.Get("folder_id")
+	if s.folderIndex(folder) < 0 {
+		return nil, fail(instapaper.ErrInvalidFolderID)
+	}
+	b.folder = folder
+	return []wireBookmark{toWireBookmark(b)}, nil
+}
+
+func (s *Server) updateReadProgress(form url.Values) (interface{}, *apiError) {
+	b, err := s.bookmarkParam(form)
+	if err != nil {
+		return nil, err
+	}
+	progress, perr := strconv.ParseFloat(form.Get("progress"), 32)
+	if perr != nil || progress < 0 || progress > 1 {
+		return nil, fail(instapaper.ErrInvalidProgress)
+	}
+	timestamp, terr := strconv.ParseInt(form.Get("progress_timestamp"), 10, 64)
+	if terr != nil || timestamp <= 0 {
+		return nil, fail(instapaper.ErrInvalidProgressTimestamp)
+	}
+	b.Progress = float32(progress)
+	b.ProgressTimestamp = timestamp
+	b.rehash()
+	return []wireBookmark{toWireBookmark(b)}, nil
+}
+
+func (s *Server) addFolderRequest(form url.Values) (interface{}, *apiError) {
+	title := strings.TrimSpace(form.Get("title"))
+	if title == "" {
+		return nil, fail(instapaper.ErrInvalidTitle)
+	}
+	for _, f := range s.folders {
+		if strings.EqualFold(f.Title, title) {
+			return nil, fail(instapaper.ErrDuplicateFolder)
+		}
+	}
+	return []wireFolder{toWireFolder(s.addFolder(title))}, nil
+}
+
+// deleteFolder removes a custom folder, its bookmarks go to the archive
+func (s *Server) deleteFolder(form url.Values) (interface{}, *apiError) {
+	id := form.Get("folder_id")
+	i := s.folderIndex(id)
+	if i < 0 {
+		return nil, fail(instapaper.ErrInvalidFolderID)
+	}
+	s.folders = append(s.folders[:i], s.folders[i+1:]...)
+	for _, b := range s.bookmarks {
+		if b.folder == id {
+			b.folder = instapaper.FolderIDArchive
+		}
+	}
+	return []interface{}{}, nil
+}
+
+// setOrder implements folders/set_order, unknown folders are ignored like the real API does
+func (s *Server) setOrder(form url.Values) (interface{}, *apiError) {
+	for _, pair := range strings.Split(form.Get("order"), ",") {
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 {
+			continue
+		}
+		position, err := strconv.Atoi(parts[1])
+		if i := s.folderIndex(parts[0]); i >= 0 && err == nil {
+			s.folders[i].Position = json.Number(strconv.Itoa(position))
+		}
+	}
+	sortFolders(s.folders)
+	return s.wireFolders(), nil
+}
+
+func sortFolders(folders []instapaper.Folder) {
+	sort.SliceStable(folders, func(i, j int) bool {
+		a, _ := folders[i].Position.Int64()
+		b, _ := folders[j].Position.Int64()
+		return a < b
+	})
+}
+
+func (s *Server) listHighlights(bookmarkID int) (interface{}, *apiError) {
+	if _, err := s.bookmarkByID(bookmarkID); err != nil {
+		return nil, err
+	}
+	highlights := []wireHighlight{}
+	for _, h := range s.highlightsOf(bookmarkID) {
+		highlights = append(highlights, toWireHighlight(h))
+	}
+	return highlights, nil
+}
+
+func (s *Server) addHighlightRequest(bookmarkID int, form url.Values) (interface{}, *apiError) {
+	if _, err := s.bookmarkByID(bookmarkID); err != nil {
+		return nil, err
+	}
+	text := form.Get("text")
+	if strings.TrimSpace(text) == "" {
+		return nil, fail(instapaper.ErrEmptyText)
+	}
+	for _, h := range s.highlightsOf(bookmarkID) {
+		if h.Text == text {
+			return nil, fail(instapaper.ErrDuplicateHighlight)
+		}
+	}
+	position, _ := strconv.Atoi(form.Get("position"))
+	return []wireHighlight{toWireHighlight(s.addHighlight(bookmarkID, text, "", position))}, nil
+}
+
+func (s *Server) deleteHighlight(highlightID int) (interface{}, *apiError) {
+	if _, ok := s.highlights[highlightID]; !ok {
+		return nil, &apiError{status: http.StatusNotFound, code: instapaper.ErrGeneric}
+	}
+	delete(s.highlights, highlightID)
+	return []interface{}{}, nil
+}
@@ -0,0 +1,544 @@
+// Package instapapertest provides an in-process fake of the Instapaper API, for testing code built on the instapaper
+// package without the network.
+//
+// The fake keeps a single account in memory: folders, bookmarks, highlights and article texts. It implements the xAuth
+// token exchange and every endpoint the instapaper package calls, answering with the same JSON and error codes as the
+// real API. Faults - rate limiting, server errors, latency - can be injected to exercise retries and timeouts.
+//
+//	srv := instapapertest.NewServer()
+//	defer srv.Close()
+//	srv.AddBookmark(instapaper.Bookmark{URL: "https://example.com", Title: "Example"}, instapaper.FolderIDUnread)
+//	client := srv.Client()
+//	bookmarks, err := client.Bookmarks.List(instapaper.DefaultBookmarkListRequestParams)
+//	...
+package instapapertest
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomodule/oauth1/oauth"
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// Credentials accepted and issued by a new Server, change the fields of the Server to use others
+const (
+	ConsumerKey    = "consumer-key"
+	ConsumerSecret = "consumer-secret"
+	Username       = "reader@example.com"
+	Password       = "password"
+	Token          = "oauth-token"
+	TokenSecret    = "oauth-token-secret"
+)
+
+// Server is a fake Instapaper API. The zero value is not usable, see NewServer
+type Server struct {
+	// URL is the API root to point clients to, see instapaper.WithBaseURL
+	URL string
+	// Username and Password are the credentials the xAuth exchange accepts
+	Username string
+	Password string
+	// Token and TokenSecret are issued by the xAuth exchange, API calls must be signed with Token
+	Token       string
+	TokenSecret string
+
+	server *httptest.Server
+
+	mu         sync.Mutex
+	user       instapaper.User
+	folders    []instapaper.Folder
+	bookmarks  map[int]*bookmark
+	highlights map[int]instapaper.Highlight
+	nextID     int
+	faults     []*Fault
+	latency    time.Duration
+	requests   []Request
+	now        func() time.Time
+}
+
+// bookmark is a stored bookmark along with where it is
+type bookmark struct {
+	instapaper.Bookmark
+	// folder is FolderIDUnread, FolderIDArchive or the ID of a custom folder
+	folder string
+	text   string
+}
+
+// Request is a request the server received
+type Request struct {
+	Path string
+	Form url.Values
+}
+
+// Fault makes the server fail requests instead of serving them
+type Fault struct {
+	// Path limits the fault to the requests of a single endpoint, like "/bookmarks/list". Empty means every request
+	Path string
+	// Times is how many requests fail, 0 means every matching request until the fault is cleared
+	Times int
+	// Status is the HTTP status of the failed responses
+	Status int
+	// ErrorCode is the Instapaper error code in the response body
+	ErrorCode int
+	// Message defaults to the message of the real API for ErrorCode
+	Message string
+	// RetryAfter is sent in the Retry-After header if set
+	RetryAfter time.Duration
+}
+
+// NewServer starts a fake API with an empty account, close it with Close
+func NewServer() *Server {
+	s := &Server{
+		Username:    Username,
+		Password:    Password,
+		Token:       Token,
+		TokenSecret: TokenSecret,
+		user: instapaper.User{
+			ID:                   1,
+			Username:             Username,
+			SubscriptionIsActive: "1",
+		},
+		bookmarks:  map[int]*bookmark{},
+		highlights: map[int]instapaper.Highlight{},
+		nextID:     1000,
+		now:        time.Now,
+	}
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a client of the server, already authenticated. opts are applied after pointing it to the server
+func (s *Server) Client(opts ...instapaper.Option) *instapaper.Client {
+	opts = append([]instapaper.Option{instapaper.WithBaseURL(s.URL)}, opts...)
+	client, _ := instapaper.NewClient(ConsumerKey, ConsumerSecret, s.Username, s.Password, opts...)
+	client.Credentials = &oauth.Credentials{Token: s.Token, Secret: s.TokenSecret}
+	return client
+}
+
+// SetUser changes the owner of the account, returned by verify_credentials and bookmarks/list
+func (s *Server) SetUser(user instapaper.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = user
+}
+
+// AddFolder creates a custom folder
+func (s *Server) AddFolder(title string) instapaper.Folder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addFolder(title)
+}
+
+// AddBookmark stores a bookmark in the folder - FolderIDUnread, FolderIDArchive or the ID of a custom folder - and
+// returns it. A zero ID, Time or Hash is filled in
+func (s *Server) AddBookmark(b instapaper.Bookmark, folder string) instapaper.Bookmark {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b.ID == 0 {
+		b.ID = s.id()
+	}
+	if b.Time == 0 {
+		b.Time = float32(s.now().Unix())
+	}
+	if folder == "" || folder == instapaper.FolderIDStarred {
+		folder = instapaper.FolderIDUnread
+	}
+	stored := &bookmark{Bookmark: b, folder: folder}
+	if b.Hash == "" {
+		stored.rehash()
+	}
+	s.bookmarks[b.ID] = stored
+	return stored.Bookmark
+}
+
+// SetText sets the text-view HTML get_text returns for the bookmark. Bookmarks without one get a page generated
+// from their title and description
+func (s *Server) SetText(bookmarkID int, html string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.bookmarks[bookmarkID]; ok {
+		b.text = html
+	}
+}
+
+// AddHighlight stores a highlight of the bookmark and returns it
+func (s *Server) AddHighlight(bookmarkID int, text, note string, position int) instapaper.Highlight {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addHighlight(bookmarkID, text, note, position)
+}
+
+// Bookmark returns the stored bookmark
+func (s *Server) Bookmark(id int) (instapaper.Bookmark, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.bookmarks[id]
+	if !ok {
+		return instapaper.Bookmark{}, false
+	}
+	return b.Bookmark, true
+}
+
+// Bookmarks returns the bookmarks in the folder, newest first like bookmarks/list
+func (s *Server) Bookmarks(folder string) []instapaper.Bookmark {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var bookmarks []instapaper.Bookmark
+	for _, b := range s.inFolder(folder) {
+		bookmarks = append(bookmarks, b.Bookmark)
+	}
+	return bookmarks
+}
+
+// Folders returns the custom folders in their order
+func (s *Server) Folders() []instapaper.Folder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]instapaper.Folder(nil), s.folders...)
+}
+
+// Highlights returns the highlights of the bookmark in the order of their position
+func (s *Server) Highlights(bookmarkID int) []instapaper.Highlight {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.highlightsOf(bookmarkID)
+}
+
+// Requests returns the requests received so far, faulty ones included
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Inject adds a fault. Faults are checked in the order they were added, the first matching one fails the request
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fault := f
+	s.faults = append(s.faults, &fault)
+}
+
+// RateLimit fails the next n requests with ErrRateLimitExceeded, asking to retry after retryAfter if it's not zero
+func (s *Server) RateLimit(n int, retryAfter time.Duration) {
+	s.Inject(Fault{Times: n, Status: http.StatusBadRequest, ErrorCode: instapaper.ErrRateLimitExceeded, RetryAfter: retryAfter})
+}
+
+// ServerError fails the next n requests with a 503 and ErrGeneric
+func (s *Server) ServerError(n int) {
+	s.Inject(Fault{Times: n, Status: http.StatusServiceUnavailable, ErrorCode: instapaper.ErrGeneric})
+}
+
+// ClearFaults removes every injected fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// SetLatency delays every response by d, a request whose context ends in the meantime gets no response
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// messages are the error messages of the real API
+var messages = map[int]string{
+	instapaper.ErrRateLimitExceeded:         "Rate-limit exceeded",
+	instapaper.ErrNotPremiumAccount:         "Premium account required",
+	instapaper.ErrApplicationSuspended:      "Application is suspended",
+	instapaper.ErrFullContentRequired:       "Domain requires full content to be supplied",
+	instapaper.ErrDomainNotSupported:        "Domain has opted out of Instapaper compatibility",
+	instapaper.ErrInvalidURL:                "Invalid URL specified",
+	instapaper.ErrInvalidBookmarkID:         "Invalid or missing bookmark_id",
+	instapaper.ErrInvalidFolderID:           "Invalid or missing folder_id",
+	instapaper.ErrInvalidProgress:           "Invalid or missing progress",
+	instapaper.ErrInvalidProgressTimestamp:  "Invalid or missing progress_timestamp",
+	instapaper.ErrSuppliedContentRequired:   "Private bookmarks require supplied content",
+	instapaper.ErrInvalidTitle:              "Invalid or missing title",
+	instapaper.ErrDuplicateFolder:           "User already has a folder with this title",
+	instapaper.ErrCannotAddBookmarkToFolder: "Cannot add bookmarks to this folder",
+	instapaper.ErrGeneric:                   "Unexpected service error",
+	instapaper.ErrTextGen:                   "Error generating text version of this URL",
+	instapaper.ErrEmptyText:                 "Cannot create highlight with empty text",
+	instapaper.ErrDuplicateHighlight:        "Duplicate highlight",
+	instapaper.ErrNotAuthenticated:          "Invalid or missing OAuth token",
+}
+
+// apiError is an error response, the status is 400 unless said otherwise
+type apiError struct {
+	status int
+	code   int
+}
+
+func fail(code int) *apiError {
+	return &apiError{status: http.StatusBadRequest, code: code}
+}
+
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	if message == "" {
+		message = messages[code]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode([]wireError{{Type: "error", ErrorCode: code, Message: message}})
+}
+
+var (
+	highlightsPath      = regexp.MustCompile(`^/bookmarks/(\d+)/highlights$`)
+	addHighlightPath    = regexp.MustCompile(`^/bookmarks/(\d+)/highlight$`)
+	deleteHighlightPath = regexp.MustCompile(`^/highlights/(\d+)/delete$`)
+)
+
+// ServeHTTP serves the API, Server is usable as a handler of another server as well
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Path: r.URL.Path, Form: r.PostForm})
+	latency := s.latency
+	fault := s.fault(r.URL.Path)
+	s.mu.Unlock()
+
+	if latency > 0 && !sleep(r.Context(), latency) {
+		return
+	}
+	if fault != nil {
+		if fault.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int((fault.RetryAfter+time.Second-1)/time.Second)))
+		}
+		writeError(w, fault.Status, fault.ErrorCode, fault.Message)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path == "/oauth/access_token" {
+		s.accessToken(w, r)
+		return
+	}
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, instapaper.ErrNotAuthenticated, "")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res interface{}
+	var err *apiError
+	path := r.URL.Path
+	switch {
+	case path == "/account/verify_credentials":
+		res = []wireUser{s.wireUser()}
+	case path == "/bookmarks/list":
+		res, err = s.list(r.PostForm)
+	case path == "/bookmarks/get_text":
+		s.getText(w, r.PostForm)
+		return
+	case path == "/bookmarks/add":
+		res, err = s.add(r.PostForm)
+	case path == "/bookmarks/delete":
+		res, err = s.deleteBookmark(r.PostForm)
+	case path == "/bookmarks/star", path == "/bookmarks/unstar", path == "/bookmarks/archive", path == "/bookmarks/unarchive":
+		res, err = s.mutate(strings.TrimPrefix(path, "/bookmarks/"), r.PostForm)
+	case path == "/bookmarks/move":
+		res, err = s.move(r.PostForm)
+	case path == "/bookmarks/update_read_progress":
+		res, err = s.updateReadProgress(r.PostForm)
+	case path == "/folders/list":
+		res = s.wireFolders()
+	case path == "/folders/add":
+		res, err = s.addFolderRequest(r.PostForm)
+	case path == "/folders/delete":
+		res, err = s.deleteFolder(r.PostForm)
+	case path == "/folders/set_order":
+		res, err = s.setOrder(r.PostForm)
+	case highlightsPath.MatchString(path):
+		res, err = s.listHighlights(pathID(highlightsPath, path))
+	case addHighlightPath.MatchString(path):
+		res, err = s.addHighlightRequest(pathID(addHighlightPath, path), r.PostForm)
+	case deleteHighlightPath.MatchString(path):
+		res, err = s.deleteHighlight(pathID(deleteHighlightPath, path))
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		writeError(w, err.status, err.code, "")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// fault returns the fault failing a request to path, if any
+func (s *Server) fault(path string) *Fault {
+	for i, f := range s.faults {
+		if f.Path != "" && f.Path != path {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// accessToken implements the xAuth exchange, answering with a form encoded token like the real API
+func (s *Server) accessToken(w http.ResponseWriter, r *http.Request) {
+	if r.PostForm.Get("x_auth_mode") != "client_auth" || r.PostForm.Get("x_auth_username") != s.Username ||
+		r.PostForm.Get("x_auth_password") != s.Password {
+		http.Error(w, "Invalid xAuth credentials.", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+	fmt.Fprint(w, url.Values{"oauth_token": {s.Token}, "oauth_token_secret": {s.TokenSecret}}.Encode())
+}
+
+var oauthToken = regexp.MustCompile(`oauth_token="([^"]*)"`)
+
+// authorized checks the token the request is signed with, signatures themselves are not verified
+func (s *Server) authorized(r *http.Request) bool {
+	m := oauthToken.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil {
+		return r.PostForm.Get("oauth_token") == s.Token
+	}
+	token, err := url.QueryUnescape(m[1])
+	return err == nil && token == s.Token
+}
+
+func pathID(re *regexp.Regexp, path string) int {
+	id, _ := strconv.Atoi(re.FindStringSubmatch(path)[1])
+	return id
+}
+
+func (s *Server) id() int {
+	s.nextID++
+	return s.nextID
+}
+
+// inFolder returns the bookmarks listed in the folder, newest first
+func (s *Server) inFolder(folder string) []*bookmark {
+	var bookmarks []*bookmark
+	for _, b := range s.bookmarks {
+		if b.folder == folder || (folder == instapaper.FolderIDStarred && b.Starred == "1") {
+			bookmarks = append(bookmarks, b)
+		}
+	}
+	sort.Slice(bookmarks, func(i, j int) bool {
+		if bookmarks[i].Time != bookmarks[j].Time {
+			return bookmarks[i].Time > bookmarks[j].Time
+		}
+		return bookmarks[i].ID > bookmarks[j].ID
+	})
+	return bookmarks
+}
+
+func (s *Server) folderIndex(id string) int {
+	for i, f := range s.folders {
+		if f.ID.String() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) addFolder(title string) instapaper.Folder {
+	id := strconv.Itoa(s.id())
+	folder := instapaper.Folder{
+		ID:           json.Number(id),
+		Title:        title,
+		Slug:         slug(title),
+		DisplayTitle: title,
+		SyncToMobile: 1,
+		Position:     json.Number(strconv.Itoa(len(s.folders) + 1)),
+	}
+	s.folders = append(s.folders, folder)
+	return folder
+}
+
+func (s *Server) addHighlight(bookmarkID int, text, note string, position int) instapaper.Highlight {
+	h := instapaper.Highlight{
+		ID:         s.id(),
+		BookmarkID: bookmarkID,
+		Text:       text,
+		Note:       note,
+		Time:       json.Number(strconv.FormatInt(s.now().Unix(), 10)),
+		Position:   position,
+	}
+	s.highlights[h.ID] = h
+	return h
+}
+
+func (s *Server) highlightsOf(bookmarkID int) []instapaper.Highlight {
+	var highlights []instapaper.Highlight
+	for _, h := range s.highlights {
+		if h.BookmarkID == bookmarkID {
+			highlights = append(highlights, h)
+		}
+	}
+	sort.Slice(highlights, func(i, j int) bool {
+		if highlights[i].Position != highlights[j].Position {
+			return highlights[i].Position < highlights[j].Position
+		}
+		return highlights[i].ID < highlights[j].ID
+	})
+	return highlights
+}
+
+// rehash updates the hash after a change, clients compare it through the "have" parameter
+func (b *bookmark) rehash() {
+	sum := md5.Sum([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%v\x00%d", b.URL, b.Title, b.Description, b.Starred,
+		b.Progress, b.ProgressTimestamp)))
+	b.Hash = fmt.Sprintf("%x", sum[:4])
+}
+
+func slug(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
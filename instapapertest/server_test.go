@@ -0,0 +1,203 @@
+package instapapertest
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+func apiErrorCode(err error) int {
+	if apiErr, ok := err.(*instapaper.APIError); ok {
+		return apiErr.ErrorCode
+	}
+	return 0
+}
+
+func TestAuthenticate(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	client, _ := instapaper.NewClient(ConsumerKey, ConsumerSecret, Username, Password,
+		instapaper.WithBaseURL(srv.URL), instapaper.WithVerifyOnAuthenticate())
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if client.Credentials.Token != Token || client.User == nil || client.User.Username != Username {
+		t.Errorf("unexpected credentials %+v and user %+v", client.Credentials, client.User)
+	}
+
+	client, _ = instapaper.NewClient(ConsumerKey, ConsumerSecret, Username, "wrong", instapaper.WithBaseURL(srv.URL))
+	if err := client.Authenticate(); err == nil {
+		t.Errorf("expected wrong credentials to be refused")
+	}
+	svc := client.Folders
+	client.Credentials = nil
+	if _, err := svc.List(); err == nil {
+		t.Errorf("expected an unauthenticated call to fail")
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	svc := srv.Client().Bookmarks
+	folderSvc := srv.Client().Folders
+
+	added, err := svc.Add(instapaper.BookmarkAddRequestParams{URL: "https://example.com/a", Title: "A"})
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if _, err := svc.Add(instapaper.BookmarkAddRequestParams{URL: "not a url"}); apiErrorCode(err) != instapaper.ErrInvalidURL {
+		t.Errorf("expected error %d, got %v", instapaper.ErrInvalidURL, err)
+	}
+	if err := svc.Star(added.ID); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if err := svc.Star(42); apiErrorCode(err) != instapaper.ErrInvalidBookmarkID {
+		t.Errorf("expected error %d, got %v", instapaper.ErrInvalidBookmarkID, err)
+	}
+	starred, err := svc.List(instapaper.BookmarkListRequestParams{Folder: instapaper.FolderIDStarred, Limit: 10})
+	if err != nil || len(starred.Bookmarks) != 1 || starred.Bookmarks[0].Starred != "1" || starred.User.Username != Username {
+		t.Errorf("expected the starred bookmark to be listed, got %+v %v", starred, err)
+	}
+
+	folder, err := folderSvc.Add("Reading")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if _, err := folderSvc.Add("reading"); apiErrorCode(err) != instapaper.ErrDuplicateFolder {
+		t.Errorf("expected error %d, got %v", instapaper.ErrDuplicateFolder, err)
+	}
+	if err := svc.Move(added.ID, folder.ID.String()); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if err := svc.Move(added.ID, instapaper.FolderIDArchive); apiErrorCode(err) != instapaper.ErrInvalidFolderID {
+		t.Errorf("expected error %d, got %v", instapaper.ErrInvalidFolderID, err)
+	}
+	if err := svc.UpdateReadProgress(added.ID, 1.5, 1); apiErrorCode(err) != instapaper.ErrInvalidProgress {
+		t.Errorf("expected error %d, got %v", instapaper.ErrInvalidProgress, err)
+	}
+	if got := srv.Bookmarks(folder.ID.String()); len(got) != 1 || got[0].ID != added.ID {
+		t.Errorf("expected the bookmark to be moved, got %+v", got)
+	}
+	if err := folderSvc.Delete(folder.ID.String()); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if got := srv.Bookmarks(instapaper.FolderIDArchive); len(got) != 1 {
+		t.Errorf("expected the bookmarks of a deleted folder to be archived, got %+v", got)
+	}
+
+	text, err := svc.GetText(added.ID)
+	if err != nil || !strings.Contains(text, "<title>A</title>") {
+		t.Errorf("expected a generated text view, got %q %v", text, err)
+	}
+	if err := svc.DeletePermanently(added.ID); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if _, ok := srv.Bookmark(added.ID); ok {
+		t.Errorf("expected the bookmark to be deleted")
+	}
+}
+
+func TestListHave(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	a := srv.AddBookmark(instapaper.Bookmark{URL: "https://example.com/a", Time: 2}, instapaper.FolderIDUnread)
+	b := srv.AddBookmark(instapaper.Bookmark{URL: "https://example.com/b", Time: 1}, instapaper.FolderIDUnread)
+	srv.AddHighlight(b.ID, "quoted", "", 0)
+	svc := srv.Client().Bookmarks
+
+	res, err := svc.List(instapaper.BookmarkListRequestParams{
+		Limit:           10,
+		CustomHaveParam: "1:x," + strings.Join([]string{strconv.Itoa(a.ID) + ":" + a.Hash, strconv.Itoa(b.ID) + ":stale"}, ","),
+	})
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if len(res.Bookmarks) != 1 || res.Bookmarks[0].ID != b.ID || len(res.Highlights) != 1 {
+		t.Errorf("expected only the changed bookmark and its highlight, got %+v", res)
+	}
+	if !reflect.DeepEqual(res.DeleteIDs, []int{1}) {
+		t.Errorf("expected the unknown bookmark to be reported as deleted, got %v", res.DeleteIDs)
+	}
+
+	it := svc.ListAll(context.Background(), instapaper.FolderIDUnread)
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.Bookmark().ID)
+	}
+	if it.Err() != nil || !reflect.DeepEqual(ids, []int{a.ID, b.ID}) {
+		t.Errorf("expected the newest bookmark first, got %v %v", ids, it.Err())
+	}
+}
+
+func TestHighlights(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	b := srv.AddBookmark(instapaper.Bookmark{URL: "https://example.com/a"}, instapaper.FolderIDUnread)
+	svc := srv.Client().Highlights
+
+	h, err := svc.Add(b.ID, "quoted", 3)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if _, err := svc.Add(b.ID, "quoted", 3); apiErrorCode(err) != instapaper.ErrDuplicateHighlight {
+		t.Errorf("expected error %d, got %v", instapaper.ErrDuplicateHighlight, err)
+	}
+	if _, err := svc.Add(b.ID, " ", 3); apiErrorCode(err) != instapaper.ErrEmptyText {
+		t.Errorf("expected error %d, got %v", instapaper.ErrEmptyText, err)
+	}
+	highlights, err := svc.List(b.ID)
+	if err != nil || len(highlights) != 1 || highlights[0].Text != "quoted" || highlights[0].Position != 3 {
+		t.Errorf("unexpected highlights %+v %v", highlights, err)
+	}
+	if err := svc.Delete(h.ID); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if len(srv.Highlights(b.ID)) != 0 {
+		t.Errorf("expected the highlight to be deleted")
+	}
+}
+
+func TestFaults(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	var retries int
+	client := srv.Client(instapaper.WithRetryPolicy(instapaper.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		OnRetry:     func(instapaper.RetryEvent) { retries++ },
+	}))
+	svc := client.Folders
+
+	srv.RateLimit(1, 0)
+	srv.ServerError(1)
+	if _, err := svc.List(); err != nil {
+		t.Errorf("expected the retries to get through, got %v", err)
+	}
+	if retries != 2 || len(srv.Requests()) != 3 {
+		t.Errorf("expected 2 retries and 3 requests, got %d and %d", retries, len(srv.Requests()))
+	}
+
+	srv.Inject(Fault{Path: "/folders/add", Status: 400, ErrorCode: instapaper.ErrApplicationSuspended})
+	if _, err := svc.Add("x"); apiErrorCode(err) != instapaper.ErrApplicationSuspended {
+		t.Errorf("expected error %d, got %v", instapaper.ErrApplicationSuspended, err)
+	}
+	if _, err := svc.List(); err != nil {
+		t.Errorf("expected other endpoints to work, got %v", err)
+	}
+	srv.ClearFaults()
+
+	srv.SetLatency(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := svc.ListContext(ctx); apiErrorCode(err) != instapaper.ErrCanceled {
+		t.Errorf("expected error %d, got %v", instapaper.ErrCanceled, err)
+	}
+}
@@ -0,0 +1,91 @@
+// Package adder adds bookmarks to an account on behalf of the importers: it creates the folders they go into on first
+// use, then adds, stars and archives the bookmarks.
+package adder
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// Adder adds bookmarks, it's not safe for concurrent use
+type Adder struct {
+	bookmarks instapaper.BookmarkAPI
+	folders   instapaper.FolderAPI
+	// DryRun makes the adder only pretend: no folder is created, no bookmark added
+	DryRun bool
+	// ids maps the lowercased titles of the custom folders to their IDs
+	ids map[string]string
+}
+
+// New returns an adder knowing the custom folders of the account
+func New(ctx context.Context, bookmarks instapaper.BookmarkAPI, folders instapaper.FolderAPI, dryRun bool) (*Adder, error) {
+	a := &Adder{
+		bookmarks: bookmarks,
+		folders:   folders,
+		DryRun:    dryRun,
+		ids:       map[string]string{},
+	}
+	return a, a.load(ctx)
+}
+
+func (a *Adder) load(ctx context.Context) error {
+	folders, err := a.folders.ListContext(ctx)
+	if err != nil {
+		return err
+	}
+	for _, folder := range folders {
+		a.ids[strings.ToLower(folder.Title)] = folder.ID.String()
+	}
+	return nil
+}
+
+// FolderID returns the ID of the custom folder titled title - case insensitively - creating it if needed. created
+// tells whether it was created, or would have been in a dry run
+func (a *Adder) FolderID(ctx context.Context, title string) (id string, created bool, err error) {
+	key := strings.ToLower(title)
+	if id, ok := a.ids[key]; ok {
+		return id, false, nil
+	}
+	if a.DryRun {
+		a.ids[key] = "dry-run"
+		return a.ids[key], true, nil
+	}
+	folder, err := a.folders.AddContext(ctx, title)
+	if errors.Is(err, instapaper.ErrDuplicate) {
+		// created since the list was fetched
+		if err := a.load(ctx); err != nil {
+			return "", false, err
+		}
+		if id, ok := a.ids[key]; ok {
+			return id, false, nil
+		}
+	}
+	if err != nil {
+		return "", false, err
+	}
+	a.ids[key] = folder.ID.String()
+	return a.ids[key], true, nil
+}
+
+// Add adds the bookmark, then stars and archives it as asked
+func (a *Adder) Add(ctx context.Context, params instapaper.BookmarkAddRequestParams, starred, archived bool) error {
+	if a.DryRun {
+		return nil
+	}
+	bookmark, err := a.bookmarks.AddContext(ctx, params)
+	if err != nil {
+		return err
+	}
+	if starred {
+		if err := a.bookmarks.StarContext(ctx, bookmark.ID); err != nil {
+			return err
+		}
+	}
+	if archived {
+		return a.bookmarks.ArchiveContext(ctx, bookmark.ID)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package netscape
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+	"github.com/ochronus/instapaper-go-client/internal/adder"
+)
+
+// Export builds a bookmark file of the whole account: a folder for every built-in and custom folder holding its
+// bookmarks. Starred bookmarks are listed both in the starred folder and the folder they live in
+func Export(ctx context.Context, client *instapaper.Client) (*Folder, error) {
+	folders, err := client.Folders.ListAllContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	root := &Folder{Title: "Instapaper"}
+	for _, folder := range folders {
+		f := &Folder{Title: folder.Title}
+		it := client.Bookmarks.ListAll(ctx, folder.ID.String())
+		for it.Next() {
+			b := it.Bookmark()
+			bookmark := Bookmark{
+				URL:         b.URL,
+				Title:       b.Title,
+				Description: b.Description,
+			}
+			if b.Time > 0 {
+				bookmark.AddDate = time.Unix(int64(b.Time), 0)
+			}
+			f.Bookmarks = append(f.Bookmarks, bookmark)
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+		root.Folders = append(root.Folders, f)
+	}
+	return root, nil
+}
+
+// Entry is a bookmark of an imported file along with where it goes
+type Entry struct {
+	Bookmark Bookmark
+	// Folder is the title of the Instapaper folder the bookmark is added to, empty for the unread folder
+	Folder  string
+	Starred bool
+	Archive bool
+	// Reason tells why the bookmark was skipped
+	Reason string
+}
+
+// ImportReport sums up an import. In a dry run Added and CreatedFolders list what would have been added or created
+type ImportReport struct {
+	CreatedFolders []string
+	Added          []Entry
+	Skipped        []Entry
+}
+
+// Importer adds the bookmarks of a file to an Instapaper account.
+//
+// Instapaper folders can't be nested, so a subfolder becomes a folder titled with its path, like "Tech / Go". Top level
+// folders named like the built-in ones are mapped onto them: their bookmarks are added to the unread folder, and then
+// starred or archived. Bookmarks whose URL is already in the account are skipped. A URL listed more than once in the
+// file is added once, starred if it's listed under Starred, in the first other folder it's listed in
+type Importer struct {
+	Client *instapaper.Client
+	// DryRun only reads the account and reports what an import would do
+	DryRun bool
+}
+
+// Import adds the bookmarks of root to the account. On error the report covers the bookmarks handled so far
+func (im *Importer) Import(ctx context.Context, root *Folder) (*ImportReport, error) {
+	report := &ImportReport{}
+
+	folders, err := im.Client.Folders.ListAllContext(ctx)
+	if err != nil {
+		return report, err
+	}
+	seen := map[string]bool{}
+	for _, folder := range folders {
+		it := im.Client.Bookmarks.ListAll(ctx, folder.ID.String())
+		for it.Next() {
+			seen[normalizeURL(it.Bookmark().URL)] = true
+		}
+		if err := it.Err(); err != nil {
+			return report, err
+		}
+	}
+
+	// a bookmark may be listed more than once - Export lists starred ones both under Starred and their actual
+	// folder - so the copies are merged before adding: starred if any copy is, in the folder of the first copy that
+	// isn't under Starred
+	var entries []*Entry
+	byURL := map[string]*Entry{}
+	placed := map[*Entry]bool{}
+	root.Walk(func(path []string, b Bookmark) error {
+		entry := entryFor(path, b)
+		key := normalizeURL(b.URL)
+		switch {
+		case !importable(b.URL):
+			entry.Reason = "unsupported URL"
+		case seen[key]:
+			entry.Reason = "duplicate URL"
+		}
+		if entry.Reason != "" {
+			report.Skipped = append(report.Skipped, entry)
+			return nil
+		}
+		listedStarred := entry.Starred
+		existing, ok := byURL[key]
+		if !ok {
+			byURL[key] = &entry
+			entries = append(entries, &entry)
+			placed[&entry] = !listedStarred
+			return nil
+		}
+		if listedStarred {
+			existing.Starred = true
+			return nil
+		}
+		if placed[existing] {
+			entry.Reason = "duplicate URL"
+			report.Skipped = append(report.Skipped, entry)
+			return nil
+		}
+		existing.Folder = entry.Folder
+		existing.Archive = entry.Archive
+		placed[existing] = true
+		return nil
+	})
+
+	add, err := adder.New(ctx, im.Client.Bookmarks, im.Client.Folders, im.DryRun)
+	if err != nil {
+		return report, err
+	}
+	for _, entry := range entries {
+		folderID := ""
+		if entry.Folder != "" {
+			id, created, err := add.FolderID(ctx, entry.Folder)
+			if err != nil {
+				return report, err
+			}
+			if created {
+				report.CreatedFolders = append(report.CreatedFolders, entry.Folder)
+			}
+			folderID = id
+		}
+		params := instapaper.BookmarkAddRequestParams{
+			URL:         entry.Bookmark.URL,
+			Title:       entry.Bookmark.Title,
+			Description: entry.Bookmark.Description,
+			Folder:      folderID,
+		}
+		if err := add.Add(ctx, params, entry.Starred, entry.Archive); err != nil {
+			return report, err
+		}
+		report.Added = append(report.Added, *entry)
+	}
+	return report, nil
+}
+
+// entryFor maps the folder path of a bookmark onto Instapaper
+func entryFor(path []string, b Bookmark) Entry {
+	entry := Entry{Bookmark: b}
+	if len(path) == 0 {
+		return entry
+	}
+	if len(path) == 1 {
+		for _, builtIn := range instapaper.BuiltInFolders {
+			if !strings.EqualFold(path[0], builtIn.Title) && !strings.EqualFold(path[0], builtIn.DisplayTitle) {
+				continue
+			}
+			switch builtIn.ID.String() {
+			case instapaper.FolderIDStarred:
+				entry.Starred = true
+			case instapaper.FolderIDArchive:
+				entry.Archive = true
+			}
+			return entry
+		}
+	}
+	entry.Folder = strings.Join(path, " / ")
+	return entry
+}
+
+func importable(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// normalizeURL makes URLs differing only in the case of the host, the fragment or a bare "/" path compare equal
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	if u.Path == "/" {
+		u.Path = ""
+	}
+	return u.String()
+}
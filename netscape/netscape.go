@@ -0,0 +1,234 @@
+// Package netscape reads and writes the Netscape bookmark file format - the bookmarks.html every browser, Pinboard and
+// most read-later services import and export - and converts between it and an Instapaper account.
+package netscape
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Folder is a folder of a bookmark file, the root of the file is a folder as well
+type Folder struct {
+	Title     string
+	AddDate   time.Time
+	Folders   []*Folder
+	Bookmarks []Bookmark
+}
+
+// Bookmark is a single link of a bookmark file
+type Bookmark struct {
+	URL         string
+	Title       string
+	Description string
+	AddDate     time.Time
+	Tags        []string
+}
+
+// Walk calls fn for every bookmark in the folder and its subfolders, depth first. path holds the titles of the folders
+// leading to the bookmark, the root folder excluded
+func (f *Folder) Walk(fn func(path []string, b Bookmark) error) error {
+	return f.walk(nil, fn)
+}
+
+func (f *Folder) walk(path []string, fn func(path []string, b Bookmark) error) error {
+	for _, b := range f.Bookmarks {
+		if err := fn(path, b); err != nil {
+			return err
+		}
+	}
+	for _, sub := range f.Folders {
+		subPath := append(append([]string(nil), path...), sub.Title)
+		if err := sub.walk(subPath, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const header = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+`
+
+// Write writes root as a bookmark file, the title of root becomes the title of the file
+func Write(w io.Writer, root *Folder) error {
+	bw := bufio.NewWriter(w)
+	title := root.Title
+	if title == "" {
+		title = "Bookmarks"
+	}
+	bw.WriteString(header)
+	fmt.Fprintf(bw, "<TITLE>%s</TITLE>\n<H1>%s</H1>\n", html.EscapeString(title), html.EscapeString(title))
+	writeList(bw, root, 0)
+	return bw.Flush()
+}
+
+func writeList(w *bufio.Writer, f *Folder, depth int) {
+	indent := strings.Repeat("    ", depth)
+	fmt.Fprintf(w, "%s<DL><p>\n", indent)
+	for _, sub := range f.Folders {
+		fmt.Fprintf(w, "%s    <DT><H3%s>%s</H3>\n", indent, dateAttr("ADD_DATE", sub.AddDate), html.EscapeString(sub.Title))
+		writeList(w, sub, depth+1)
+	}
+	for _, b := range f.Bookmarks {
+		fmt.Fprintf(w, "%s    <DT><A HREF=\"%s\"%s", indent, html.EscapeString(b.URL), dateAttr("ADD_DATE", b.AddDate))
+		if len(b.Tags) > 0 {
+			fmt.Fprintf(w, " TAGS=\"%s\"", html.EscapeString(strings.Join(b.Tags, ",")))
+		}
+		fmt.Fprintf(w, ">%s</A>\n", html.EscapeString(b.Title))
+		if b.Description != "" {
+			fmt.Fprintf(w, "%s    <DD>%s\n", indent, html.EscapeString(b.Description))
+		}
+	}
+	fmt.Fprintf(w, "%s</DL><p>\n", indent)
+}
+
+func dateAttr(name string, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" %s=\"%d\"", name, t.Unix())
+}
+
+// Parse reads a bookmark file. The format is loosely specified HTML - DT and DD are never closed, P tags are sprinkled
+// around - so the file is read token by token rather than as a document tree.
+// Look-alikes such as Pocket's export are understood too: lists may be UL instead of DL, headings after the first H1
+// start top level folders and the add date may be in a time_added attribute
+func Parse(r io.Reader) (*Folder, error) {
+	root := &Folder{}
+	stack := []*Folder{}
+	var pending *Folder // the folder whose heading was read last, its DL comes next
+	var text strings.Builder
+	var capture func(string)
+	var lastBookmark *Bookmark
+
+	z := nethtml.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		switch tt {
+		case nethtml.ErrorToken:
+			if z.Err() == io.EOF {
+				return root, nil
+			}
+			return nil, z.Err()
+		case nethtml.TextToken:
+			text.Write(z.Text())
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			token := z.Token()
+			if capture != nil && token.DataAtom != atom.P {
+				capture(text.String())
+				capture = nil
+			}
+			text.Reset()
+			current := root
+			if len(stack) > 0 {
+				current = stack[len(stack)-1]
+			}
+			switch token.DataAtom {
+			case atom.H1:
+				if root.Title != "" || len(root.Bookmarks) > 0 || len(root.Folders) > 0 {
+					folder := &Folder{}
+					root.Folders = append(root.Folders, folder)
+					pending = folder
+					lastBookmark = nil
+					capture = func(s string) { folder.Title = strings.TrimSpace(s) }
+					break
+				}
+				capture = func(s string) { root.Title = strings.TrimSpace(s) }
+			case atom.H3:
+				folder := &Folder{AddDate: parseDate(attr(token, "add_date"))}
+				current.Folders = append(current.Folders, folder)
+				pending = folder
+				lastBookmark = nil
+				capture = func(s string) { folder.Title = strings.TrimSpace(s) }
+			case atom.Dl, atom.Ul:
+				if pending != nil {
+					stack = append(stack, pending)
+					pending = nil
+				} else if len(stack) == 0 {
+					stack = append(stack, root)
+				} else {
+					// a list without a heading, keep its links in the enclosing folder
+					stack = append(stack, current)
+				}
+				lastBookmark = nil
+			case atom.A:
+				current.Bookmarks = append(current.Bookmarks, Bookmark{
+					URL:     strings.TrimSpace(attr(token, "href")),
+					AddDate: parseDate(attr(token, "add_date", "time_added")),
+					Tags:    splitTags(attr(token, "tags")),
+				})
+				b := &current.Bookmarks[len(current.Bookmarks)-1]
+				lastBookmark = b
+				capture = func(s string) { b.Title = strings.TrimSpace(s) }
+			case atom.Dd:
+				if b := lastBookmark; b != nil {
+					capture = func(s string) { b.Description = strings.TrimSpace(s) }
+				}
+			}
+		case nethtml.EndTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.H1, atom.H3, atom.A:
+				if capture != nil {
+					capture(text.String())
+					capture = nil
+				}
+			case atom.Dl, atom.Ul:
+				if capture != nil {
+					capture(text.String())
+					capture = nil
+				}
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+				lastBookmark = nil
+			}
+			text.Reset()
+		}
+	}
+}
+
+// attr returns the first of the named attributes the token has
+func attr(t nethtml.Token, names ...string) string {
+	for _, name := range names {
+		for _, a := range t.Attr {
+			if a.Key == name {
+				return a.Val
+			}
+		}
+	}
+	return ""
+}
+
+// parseDate reads a Unix timestamp, some browsers write it in microseconds
+func parseDate(s string) time.Time {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	if n > 1e14 {
+		return time.Unix(0, n*int64(time.Microsecond))
+	}
+	return time.Unix(n, 0)
+}
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,244 @@
+package netscape
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomodule/oauth1/oauth"
+	"github.com/ochronus/instapaper-go-client/instapaper"
+	"github.com/ochronus/instapaper-go-client/instapapertest"
+)
+
+const firefoxExport = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file. -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks Menu</H1>
+
+<DL><p>
+    <DT><A HREF="https://golang.org/" ADD_DATE="1601750016" TAGS="go, lang">The Go &amp; Programming Language</A>
+    <DD>Build simple, reliable software
+    <DT><H3 ADD_DATE="1601750000">Tech</H3>
+    <DL><p>
+        <DT><A HREF="https://example.com/a">A</A>
+        <DT><H3>Go</H3>
+        <DL><p>
+            <DT><A HREF="https://example.com/b" ADD_DATE="1601750016000000">B</A>
+        </DL><p>
+    </DL><p>
+    <DT><A HREF="place:sort=8&amp;maxResults=10">Most Visited</A>
+</DL>
+`
+
+func TestParse(t *testing.T) {
+	root, err := Parse(strings.NewReader(firefoxExport))
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	saved := time.Unix(1601750016, 0)
+	expected := &Folder{
+		Title: "Bookmarks Menu",
+		Folders: []*Folder{{
+			Title:     "Tech",
+			AddDate:   time.Unix(1601750000, 0),
+			Bookmarks: []Bookmark{{URL: "https://example.com/a", Title: "A"}},
+			Folders: []*Folder{{
+				Title:     "Go",
+				Bookmarks: []Bookmark{{URL: "https://example.com/b", Title: "B", AddDate: saved}},
+			}},
+		}},
+		Bookmarks: []Bookmark{
+			{URL: "https://golang.org/", Title: "The Go & Programming Language", Description: "Build simple, reliable software", AddDate: saved, Tags: []string{"go", "lang"}},
+			{URL: "place:sort=8&maxResults=10", Title: "Most Visited"},
+		},
+	}
+	if !reflect.DeepEqual(root, expected) {
+		t.Errorf("expected %+v, got %+v", expected, root)
+	}
+}
+
+func TestWriteParseRoundTrip(t *testing.T) {
+	root, _ := Parse(strings.NewReader(firefoxExport))
+	var buf bytes.Buffer
+	if err := Write(&buf, root); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<!DOCTYPE NETSCAPE-Bookmark-file-1>") {
+		t.Errorf("expected the Netscape doctype, got %v", buf.String())
+	}
+	again, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if !reflect.DeepEqual(root, again) {
+		t.Errorf("expected %+v, got %+v", root, again)
+	}
+}
+
+// fakeAccount serves an account with a custom "Tech" folder and a single bookmark in it, recording the mutations
+func fakeAccount(t *testing.T, calls *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/folders/list":
+			fmt.Fprint(w, `[{"folder_id":100,"title":"Tech"}]`)
+		case "/bookmarks/list":
+			if r.FormValue("folder_id") == "100" {
+				fmt.Fprint(w, `{"bookmarks":[{"bookmark_id":1,"title":"A","url":"https://EXAMPLE.com/a","time":1601750016}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"bookmarks":[]}`)
+		case "/folders/add":
+			*calls = append(*calls, "folders/add "+r.FormValue("title"))
+			fmt.Fprint(w, `[{"type":"folder","folder_id":200,"title":"`+r.FormValue("title")+`"}]`)
+		case "/bookmarks/add":
+			*calls = append(*calls, fmt.Sprintf("bookmarks/add %v %v", r.FormValue("url"), r.FormValue("folder_id")))
+			fmt.Fprint(w, `[{"type":"bookmark","bookmark_id":300}]`)
+		case "/bookmarks/star", "/bookmarks/archive":
+			*calls = append(*calls, r.URL.Path[1:]+" "+r.FormValue("bookmark_id"))
+			fmt.Fprint(w, `[{"type":"bookmark","bookmark_id":300}]`)
+		default:
+			t.Errorf("unexpected call to %v", r.URL.Path)
+		}
+	}))
+}
+
+func testClient(server *httptest.Server) *instapaper.Client {
+	client, _ := instapaper.NewClient("client_id", "client_secret", "username", "password", instapaper.WithBaseURL(server.URL))
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+	return client
+}
+
+func TestExport(t *testing.T) {
+	var calls []string
+	server := fakeAccount(t, &calls)
+	defer server.Close()
+	root, err := Export(context.Background(), testClient(server))
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	var titles []string
+	for _, f := range root.Folders {
+		titles = append(titles, f.Title)
+	}
+	if expected := []string{"Unread", "Starred", "Archive", "Tech"}; !reflect.DeepEqual(titles, expected) {
+		t.Errorf("expected folders %v, got %v", expected, titles)
+	}
+	expected := []Bookmark{{URL: "https://EXAMPLE.com/a", Title: "A", AddDate: time.Unix(1601750016, 0)}}
+	if !reflect.DeepEqual(root.Folders[3].Bookmarks, expected) {
+		t.Errorf("expected %+v, got %+v", expected, root.Folders[3].Bookmarks)
+	}
+}
+
+func TestImport(t *testing.T) {
+	root := &Folder{
+		Bookmarks: []Bookmark{{URL: "https://example.com/new", Title: "New"}},
+		Folders: []*Folder{
+			{Title: "Tech", Bookmarks: []Bookmark{
+				{URL: "https://example.com/a#comments", Title: "Already saved"},
+				{URL: "https://example.com/c", Title: "C"},
+			}, Folders: []*Folder{
+				{Title: "Go", Bookmarks: []Bookmark{{URL: "https://example.com/d"}, {URL: "https://example.com/d"}}},
+			}},
+			{Title: "Liked", Bookmarks: []Bookmark{{URL: "https://example.com/liked"}}},
+			{Title: "Archive", Bookmarks: []Bookmark{{URL: "javascript:alert(1)"}, {URL: "https://example.com/old"}}},
+		},
+	}
+
+	var calls []string
+	server := fakeAccount(t, &calls)
+	defer server.Close()
+	im := &Importer{Client: testClient(server), DryRun: true}
+	report, err := im.Import(context.Background(), root)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("expected a dry run not to change the account, got %v", calls)
+	}
+	if expected := []string{"Tech / Go"}; !reflect.DeepEqual(report.CreatedFolders, expected) {
+		t.Errorf("expected created folders %v, got %v", expected, report.CreatedFolders)
+	}
+	if len(report.Added) != 5 || len(report.Skipped) != 3 {
+		t.Errorf("expected 5 added and 3 skipped bookmarks, got %+v", report)
+	}
+
+	im.DryRun = false
+	if _, err := im.Import(context.Background(), root); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	expected := []string{
+		"bookmarks/add https://example.com/new ",
+		"bookmarks/add https://example.com/c 100",
+		"folders/add Tech / Go",
+		"bookmarks/add https://example.com/d 200",
+		"bookmarks/add https://example.com/liked ",
+		"bookmarks/star 300",
+		"bookmarks/add https://example.com/old ",
+		"bookmarks/archive 300",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls\n%v\ngot\n%v", strings.Join(expected, "\n"), strings.Join(calls, "\n"))
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := instapapertest.NewServer()
+	defer src.Close()
+	reading := src.AddFolder("Reading")
+	src.AddBookmark(instapaper.Bookmark{URL: "https://example.com/unread", Starred: "1"}, instapaper.FolderIDUnread)
+	src.AddBookmark(instapaper.Bookmark{URL: "https://example.com/archived", Starred: "1"}, instapaper.FolderIDArchive)
+	src.AddBookmark(instapaper.Bookmark{URL: "https://example.com/reading", Starred: "1"}, reading.ID.String())
+	src.AddBookmark(instapaper.Bookmark{URL: "https://example.com/plain"}, instapaper.FolderIDUnread)
+
+	ctx := context.Background()
+	root, err := Export(ctx, src.Client())
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	var file bytes.Buffer
+	if err := Write(&file, root); err != nil {
+		t.Fatal(err)
+	}
+	if root, err = Parse(&file); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := instapapertest.NewServer()
+	defer dst.Close()
+	im := &Importer{Client: dst.Client()}
+	report, err := im.Import(ctx, root)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if len(report.Added) != 4 || len(report.Skipped) != 0 {
+		t.Errorf("expected 4 added and no skipped bookmarks, got %+v", report)
+	}
+
+	folders := dst.Folders()
+	if len(folders) != 1 || folders[0].Title != "Reading" {
+		t.Fatalf("expected the Reading folder to be created, got %v", folders)
+	}
+	state := func(folder string) map[string]string {
+		m := map[string]string{}
+		for _, b := range dst.Bookmarks(folder) {
+			m[b.URL] = b.Starred
+		}
+		return m
+	}
+	if expected := map[string]string{"https://example.com/unread": "1", "https://example.com/plain": "0"}; !reflect.DeepEqual(state(instapaper.FolderIDUnread), expected) {
+		t.Errorf("expected unread %v, got %v", expected, state(instapaper.FolderIDUnread))
+	}
+	if expected := map[string]string{"https://example.com/archived": "1"}; !reflect.DeepEqual(state(instapaper.FolderIDArchive), expected) {
+		t.Errorf("expected archive %v, got %v", expected, state(instapaper.FolderIDArchive))
+	}
+	if expected := map[string]string{"https://example.com/reading": "1"}; !reflect.DeepEqual(state(folders[0].ID.String()), expected) {
+		t.Errorf("expected Reading %v, got %v", expected, state(folders[0].ID.String()))
+	}
+}
@@ -0,0 +1,299 @@
+// Package outbox makes bookmark mutations survive network outages. Calls that fail because the API can't be reached
+// or is temporarily failing - rate limiting, service errors - are recorded durably on disk and replayed in order later,
+// see Outbox.Replay.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// ErrQueued is returned by the mutating methods of Outbox when the operation couldn't be sent and was queued instead.
+// It's not a failure - the operation is going to be replayed later
+var ErrQueued = errors.New("outbox: operation queued for replay")
+
+// Kind identifies the mutation an Op performs
+type Kind string
+
+// The supported mutations
+const (
+	KindStar               Kind = "star"
+	KindUnStar             Kind = "unstar"
+	KindArchive            Kind = "archive"
+	KindUnArchive          Kind = "unarchive"
+	KindMove               Kind = "move"
+	KindUpdateReadProgress Kind = "update_read_progress"
+	KindAddHighlight       Kind = "add_highlight"
+)
+
+// slot groups the kinds that set the same piece of state - of the queued ops in a slot only the last one matters
+func (k Kind) slot() string {
+	switch k {
+	case KindStar, KindUnStar:
+		return "starred"
+	case KindArchive, KindUnArchive, KindMove:
+		return "location"
+	case KindUpdateReadProgress:
+		return "progress"
+	}
+	return ""
+}
+
+// Op is a single queued mutation
+type Op struct {
+	Seq               int64
+	Kind              Kind
+	BookmarkID        int
+	FolderID          string  `json:",omitempty"`
+	Progress          float32 `json:",omitempty"`
+	ProgressTimestamp int64   `json:",omitempty"`
+	Text              string  `json:",omitempty"`
+	Position          int     `json:",omitempty"`
+	QueuedAt          time.Time
+}
+
+// Conflict is a queued op the API rejected during replay, e.g. with ErrInvalidBookmarkID because the bookmark was
+// deleted in the meantime. Conflicting ops are dropped from the queue
+type Conflict struct {
+	Op  Op
+	Err error
+}
+
+// ReplayReport summarizes a Replay
+type ReplayReport struct {
+	Applied   []Op
+	Conflicts []Conflict
+	Remaining int
+}
+
+// Outbox sends mutations right away when possible and queues them when the network or the API is unavailable.
+// It's safe for concurrent use
+type Outbox struct {
+	mu         sync.Mutex
+	path       string
+	ops        []Op
+	nextSeq    int64
+	bookmarks  instapaper.BookmarkAPI
+	highlights instapaper.HighlightAPI
+}
+
+// Open loads the queue stored at path - a missing file means an empty queue - and returns an outbox sending the ops
+// through client
+func Open(path string, client *instapaper.Client) (*Outbox, error) {
+	return OpenWith(path, client.Bookmarks, client.Highlights)
+}
+
+// OpenWith is like Open but the ops are sent through the given services - e.g. mocks in tests
+func OpenWith(path string, bookmarks instapaper.BookmarkAPI, highlights instapaper.HighlightAPI) (*Outbox, error) {
+	o := &Outbox{
+		path:       path,
+		nextSeq:    1,
+		bookmarks:  bookmarks,
+		highlights: highlights,
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &o.ops); err != nil {
+		return nil, err
+	}
+	for _, op := range o.ops {
+		if op.Seq >= o.nextSeq {
+			o.nextSeq = op.Seq + 1
+		}
+	}
+	return o, nil
+}
+
+// Pending returns a copy of the queued ops in replay order
+func (o *Outbox) Pending() []Op {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Op(nil), o.ops...)
+}
+
+// Star stars the bookmark or queues the operation
+func (o *Outbox) Star(ctx context.Context, bookmarkID int) error {
+	return o.do(ctx, Op{Kind: KindStar, BookmarkID: bookmarkID})
+}
+
+// UnStar un-stars the bookmark or queues the operation
+func (o *Outbox) UnStar(ctx context.Context, bookmarkID int) error {
+	return o.do(ctx, Op{Kind: KindUnStar, BookmarkID: bookmarkID})
+}
+
+// Archive archives the bookmark or queues the operation
+func (o *Outbox) Archive(ctx context.Context, bookmarkID int) error {
+	return o.do(ctx, Op{Kind: KindArchive, BookmarkID: bookmarkID})
+}
+
+// UnArchive un-archives the bookmark or queues the operation
+func (o *Outbox) UnArchive(ctx context.Context, bookmarkID int) error {
+	return o.do(ctx, Op{Kind: KindUnArchive, BookmarkID: bookmarkID})
+}
+
+// Move moves the bookmark to the folder or queues the operation
+func (o *Outbox) Move(ctx context.Context, bookmarkID int, folderID string) error {
+	return o.do(ctx, Op{Kind: KindMove, BookmarkID: bookmarkID, FolderID: folderID})
+}
+
+// UpdateReadProgress updates the read progress of the bookmark or queues the operation.
+// A zero when means now - it's resolved at the time of the call, not the replay
+func (o *Outbox) UpdateReadProgress(ctx context.Context, bookmarkID int, progress float32, when int64) error {
+	if when == 0 {
+		when = time.Now().Unix()
+	}
+	return o.do(ctx, Op{Kind: KindUpdateReadProgress, BookmarkID: bookmarkID, Progress: progress, ProgressTimestamp: when})
+}
+
+// AddHighlight adds a highlight to the bookmark or queues the operation. Unlike HighlightService.Add it doesn't
+// return the created highlight
+func (o *Outbox) AddHighlight(ctx context.Context, bookmarkID int, text string, position int) error {
+	return o.do(ctx, Op{Kind: KindAddHighlight, BookmarkID: bookmarkID, Text: text, Position: position})
+}
+
+// do sends the op unless there are queued ones - they have to go first to keep the order - and queues it if the
+// network or the API is unavailable
+func (o *Outbox) do(ctx context.Context, op Op) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.ops) == 0 {
+		err := o.send(ctx, op)
+		if !isTemporary(err) {
+			return err
+		}
+	}
+	if err := o.enqueue(op); err != nil {
+		return err
+	}
+	return ErrQueued
+}
+
+// enqueue appends the op dropping the queued ones it makes redundant, e.g. a star followed by an unstar is just an unstar
+func (o *Outbox) enqueue(op Op) error {
+	op.Seq = o.nextSeq
+	op.QueuedAt = time.Now()
+	ops := make([]Op, 0, len(o.ops)+1)
+	for _, queued := range o.ops {
+		if slot := op.Kind.slot(); slot != "" && queued.BookmarkID == op.BookmarkID && queued.Kind.slot() == slot {
+			continue
+		}
+		ops = append(ops, queued)
+	}
+	ops = append(ops, op)
+	if err := o.save(ops); err != nil {
+		return err
+	}
+	o.ops = ops
+	o.nextSeq++
+	return nil
+}
+
+// Replay sends the queued ops in order. It stops at the first network error or temporary API failure leaving the rest
+// queued; ops the API rejects are reported as conflicts and dropped
+func (o *Outbox) Replay(ctx context.Context) (*ReplayReport, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	report := &ReplayReport{}
+	for len(o.ops) > 0 {
+		op := o.ops[0]
+		err := o.send(ctx, op)
+		if isTemporary(err) || isCanceled(err) {
+			report.Remaining = len(o.ops)
+			return report, err
+		}
+		if err != nil {
+			report.Conflicts = append(report.Conflicts, Conflict{Op: op, Err: err})
+		} else {
+			report.Applied = append(report.Applied, op)
+		}
+		if err := o.save(o.ops[1:]); err != nil {
+			report.Remaining = len(o.ops)
+			return report, err
+		}
+		o.ops = o.ops[1:]
+	}
+	return report, nil
+}
+
+func (o *Outbox) send(ctx context.Context, op Op) error {
+	switch op.Kind {
+	case KindStar:
+		return o.bookmarks.StarContext(ctx, op.BookmarkID)
+	case KindUnStar:
+		return o.bookmarks.UnStarContext(ctx, op.BookmarkID)
+	case KindArchive:
+		return o.bookmarks.ArchiveContext(ctx, op.BookmarkID)
+	case KindUnArchive:
+		return o.bookmarks.UnArchiveContext(ctx, op.BookmarkID)
+	case KindMove:
+		return o.bookmarks.MoveContext(ctx, op.BookmarkID, op.FolderID)
+	case KindUpdateReadProgress:
+		return o.bookmarks.UpdateReadProgressContext(ctx, op.BookmarkID, op.Progress, op.ProgressTimestamp)
+	case KindAddHighlight:
+		_, err := o.highlights.AddContext(ctx, op.BookmarkID, op.Text, op.Position)
+		return err
+	}
+	return errors.New("outbox: unknown operation " + string(op.Kind))
+}
+
+// save writes the queue atomically, so a crash never leaves a half written file behind
+func (o *Outbox) save(ops []Op) error {
+	if ops == nil {
+		ops = []Op{}
+	}
+	data, err := json.MarshalIndent(ops, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(o.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, ".outbox-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), o.path)
+}
+
+// isTemporary tells whether the op is worth sending again later: the request didn't get a response from the API, it
+// was rate limited or the service failed
+func isTemporary(err error) bool {
+	return isNetworkError(err) || instapaper.IsRetryable(err)
+}
+
+// isNetworkError tells whether the request failed without getting a response from the API
+func isNetworkError(err error) bool {
+	var apiErr *instapaper.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode == instapaper.ErrHTTPError && apiErr.StatusCode == 0
+}
+
+func isCanceled(err error) bool {
+	var apiErr *instapaper.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode == instapaper.ErrCanceled
+}
@@ -0,0 +1,179 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gomodule/oauth1/oauth"
+	"github.com/ochronus/instapaper-go-client/instapaper"
+	"github.com/ochronus/instapaper-go-client/instapapermock"
+)
+
+// switchableTransport fails every request while offline is set
+type switchableTransport struct {
+	offline bool
+}
+
+func (st *switchableTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if st.offline {
+		return nil, errors.New("network is unreachable")
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestOutbox(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.Path+" "+r.FormValue("bookmark_id"))
+		if r.FormValue("bookmark_id") == "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `[{"error_code":1241,"message":"Invalid or missing bookmark_id"}]`)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+	transport := &switchableTransport{offline: true}
+	client, _ := instapaper.NewClient("client_id", "client_secret", "username", "password",
+		instapaper.WithBaseURL(server.URL),
+		instapaper.WithHTTPClient(&http.Client{Transport: transport}),
+	)
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+
+	dir, err := ioutil.TempDir("", "instapaper-outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox.json")
+	ctx := context.Background()
+
+	o, err := Open(path, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range []error{
+		o.Star(ctx, 1),
+		o.Archive(ctx, 2),
+		o.UnStar(ctx, 1),
+		o.Move(ctx, 1, "100"),
+	} {
+		if err != ErrQueued {
+			t.Errorf("expected ErrQueued, got %v", err)
+		}
+	}
+
+	// a fresh outbox - e.g. after a restart - sees the same queue
+	o, err = Open(path, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []Kind
+	for _, op := range o.Pending() {
+		kinds = append(kinds, op.Kind)
+	}
+	if !reflect.DeepEqual(kinds, []Kind{KindArchive, KindUnStar, KindMove}) {
+		t.Errorf("expected the star to be collapsed into the unstar, got %v", kinds)
+	}
+
+	report, err := o.Replay(ctx)
+	if !isNetworkError(err) || report.Remaining != 3 {
+		t.Errorf("expected the replay to stop on the network error, got %v, %v", report, err)
+	}
+
+	transport.offline = false
+	report, err = o.Replay(ctx)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if len(report.Applied) != 2 || len(report.Conflicts) != 1 || report.Conflicts[0].Op.BookmarkID != 2 {
+		t.Errorf("expected 2 applied ops and a conflict for bookmark 2, got %v", report)
+	}
+	expectedCalls := []string{"/bookmarks/archive 2", "/bookmarks/unstar 1", "/bookmarks/move 1"}
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Errorf("expected the calls %v, got %v", expectedCalls, calls)
+	}
+	if len(o.Pending()) != 0 {
+		t.Errorf("expected the queue to be empty, got %v", o.Pending())
+	}
+
+	// with the network back and nothing queued, calls go straight through
+	if err := o.Star(ctx, 1); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+}
+
+func TestOutboxTemporaryFailures(t *testing.T) {
+	failures := 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `[{"error_code":1040,"message":"Rate-limit exceeded"}]`)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+	client, _ := instapaper.NewClient("client_id", "client_secret", "username", "password", instapaper.WithBaseURL(server.URL))
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+
+	dir, err := ioutil.TempDir("", "instapaper-outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+	o, err := Open(filepath.Join(dir, "outbox.json"), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.Star(ctx, 1); err != ErrQueued {
+		t.Errorf("expected the rate limited call to be queued, got %v", err)
+	}
+	report, err := o.Replay(ctx)
+	if !errors.Is(err, instapaper.ErrRateLimited) || report.Remaining != 1 || len(report.Conflicts) != 0 {
+		t.Errorf("expected the replay to stop on the rate limit keeping the op, got %v, %v", report, err)
+	}
+	report, err = o.Replay(ctx)
+	if err != nil || len(report.Applied) != 1 {
+		t.Errorf("expected the op to be applied once the API recovered, got %v, %v", report, err)
+	}
+}
+
+func TestOutboxWithMocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "instapaper-outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bookmarks := &instapapermock.BookmarkAPI{}
+	highlights := &instapapermock.HighlightAPI{
+		AddContextFunc: func(ctx context.Context, bookmarkID int, text string, position int) (*instapaper.Highlight, error) {
+			return nil, &instapaper.APIError{ErrorCode: instapaper.ErrGeneric}
+		},
+	}
+	o, err := OpenWith(filepath.Join(dir, "outbox.json"), bookmarks, highlights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := o.Archive(ctx, 1); err != nil {
+		t.Errorf("expected err to be nil, got %v", err)
+	}
+	if err := o.AddHighlight(ctx, 1, "quote", 0); err != ErrQueued {
+		t.Errorf("expected the failed highlight to be queued, got %v", err)
+	}
+	if calls := bookmarks.CallsTo("ArchiveContext"); len(calls) != 1 || calls[0].Args[1] != 1 {
+		t.Errorf("expected bookmark 1 to be archived, got %+v", calls)
+	}
+}
@@ -0,0 +1,359 @@
+// Package search provides full-text search over saved articles. It keeps a pure Go inverted index of bookmark titles,
+// descriptions, URLs, article texts and highlights, ranks matches with BM25 and supports phrase queries as well as
+// folder and starred filters.
+//
+// Query syntax: bare words must all be present, "quoted phrases" must be present word by word, folder:<folder id>
+// restricts the results to a folder and is:starred to starred bookmarks.
+package search
+
+import (
+	"encoding/gob"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// BM25 parameters, the usual defaults
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// fieldGap separates the positions of different fields so phrases never match across them
+const fieldGap = 100
+
+// snippetRadius is the number of words shown on each side of the first match
+const snippetRadius = 12
+
+// Document is everything indexed about a single bookmark
+type Document struct {
+	Bookmark instapaper.Bookmark
+	// Folders are the IDs of the folders the bookmark is in, used by the folder: filter
+	Folders []string
+	// Text is the article's text-view HTML, see BookmarkService.GetText
+	Text       string
+	Highlights []instapaper.Highlight
+}
+
+// SearchResult is a matching bookmark
+type SearchResult struct {
+	Bookmark instapaper.Bookmark
+	Score    float64
+	// Snippet is a short excerpt around the first match
+	Snippet string
+}
+
+// indexedDocument is what the index keeps about a document
+type indexedDocument struct {
+	Bookmark instapaper.Bookmark
+	Folders  []string
+	Length   int
+	// Content is the plain text of every field, snippets are cut from it
+	Content string
+	Terms   []string
+}
+
+// Index is an in-memory inverted index of documents. It's safe for concurrent use
+type Index struct {
+	mu          sync.RWMutex
+	docs        map[int]*indexedDocument
+	postings    map[string]map[int][]int // term -> bookmark ID -> positions
+	totalLength int
+}
+
+// NewIndex returns an empty index
+func NewIndex() *Index {
+	return &Index{
+		docs:     map[int]*indexedDocument{},
+		postings: map[string]map[int][]int{},
+	}
+}
+
+// Len returns the number of indexed documents
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Add indexes the document, replacing the bookmark's earlier version if there's one
+func (ix *Index) Add(doc Document) {
+	fields := []string{
+		doc.Bookmark.Title,
+		doc.Bookmark.Description,
+		doc.Bookmark.URL,
+		htmlToText(doc.Text),
+	}
+	for _, highlight := range doc.Highlights {
+		fields = append(fields, highlight.Text, highlight.Note)
+	}
+
+	positions := map[string][]int{}
+	var content strings.Builder
+	position, length := 0, 0
+	for _, field := range fields {
+		if field == "" {
+			continue
+		}
+		for _, t := range tokenize(field) {
+			positions[t.term] = append(positions[t.term], position)
+			position++
+			length++
+		}
+		position += fieldGap
+		content.WriteString(field)
+		content.WriteString("\n")
+	}
+
+	indexed := &indexedDocument{
+		Bookmark: doc.Bookmark,
+		Folders:  doc.Folders,
+		Length:   length,
+		Content:  content.String(),
+	}
+	for term := range positions {
+		indexed.Terms = append(indexed.Terms, term)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(doc.Bookmark.ID)
+	ix.docs[doc.Bookmark.ID] = indexed
+	ix.totalLength += length
+	for term, termPositions := range positions {
+		if ix.postings[term] == nil {
+			ix.postings[term] = map[int][]int{}
+		}
+		ix.postings[term][doc.Bookmark.ID] = termPositions
+	}
+}
+
+// Remove drops the bookmark from the index
+func (ix *Index) Remove(bookmarkID int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(bookmarkID)
+}
+
+func (ix *Index) remove(bookmarkID int) {
+	doc, ok := ix.docs[bookmarkID]
+	if !ok {
+		return
+	}
+	for _, term := range doc.Terms {
+		delete(ix.postings[term], bookmarkID)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	ix.totalLength -= doc.Length
+	delete(ix.docs, bookmarkID)
+}
+
+// Search returns the bookmarks matching the query, best matches first. See the package documentation for the syntax
+func (ix *Index) Search(queryString string) []SearchResult {
+	q := parseQuery(queryString)
+	terms := q.allTerms()
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var results []SearchResult
+	for id, doc := range ix.candidates(terms) {
+		if !ix.matches(id, doc, q) {
+			continue
+		}
+		results = append(results, SearchResult{
+			Bookmark: doc.Bookmark,
+			Score:    ix.score(id, doc, terms),
+			Snippet:  snippet(doc.Content, terms),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Bookmark.Time != results[j].Bookmark.Time {
+			return results[i].Bookmark.Time > results[j].Bookmark.Time
+		}
+		return results[i].Bookmark.ID > results[j].Bookmark.ID
+	})
+	return results
+}
+
+// candidates returns the documents containing every term - all documents when there are no terms
+func (ix *Index) candidates(terms []string) map[int]*indexedDocument {
+	if len(terms) == 0 {
+		return ix.docs
+	}
+	// start from the rarest term to keep the intersection cheap
+	sorted := append([]string(nil), terms...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(ix.postings[sorted[i]]) < len(ix.postings[sorted[j]])
+	})
+	candidates := map[int]*indexedDocument{}
+	for id := range ix.postings[sorted[0]] {
+		candidates[id] = ix.docs[id]
+	}
+	for _, term := range sorted[1:] {
+		for id := range candidates {
+			if _, ok := ix.postings[term][id]; !ok {
+				delete(candidates, id)
+			}
+		}
+	}
+	return candidates
+}
+
+// matches applies the filters and phrases of the query to a candidate
+func (ix *Index) matches(id int, doc *indexedDocument, q query) bool {
+	if q.starredOnly && doc.Bookmark.Starred != "1" {
+		return false
+	}
+	if q.folder != "" {
+		inFolder := false
+		for _, folder := range doc.Folders {
+			if folder == q.folder {
+				inFolder = true
+				break
+			}
+		}
+		if !inFolder {
+			return false
+		}
+	}
+	for _, phrase := range q.phrases {
+		if !ix.hasPhrase(id, phrase) {
+			return false
+		}
+	}
+	return true
+}
+
+func (ix *Index) hasPhrase(id int, phrase []string) bool {
+	for _, start := range ix.postings[phrase[0]][id] {
+		found := true
+		for offset, term := range phrase[1:] {
+			if !containsInt(ix.postings[term][id], start+offset+1) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt looks for n in the sorted positions
+func containsInt(positions []int, n int) bool {
+	i := sort.SearchInts(positions, n)
+	return i < len(positions) && positions[i] == n
+}
+
+// score is the BM25 score of the document for the terms
+func (ix *Index) score(id int, doc *indexedDocument, terms []string) float64 {
+	if len(ix.docs) == 0 || ix.totalLength == 0 {
+		return 0
+	}
+	n := float64(len(ix.docs))
+	avgLength := float64(ix.totalLength) / n
+	score := 0.0
+	for _, term := range terms {
+		df := float64(len(ix.postings[term]))
+		tf := float64(len(ix.postings[term][id]))
+		if tf == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		score += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(doc.Length)/avgLength))
+	}
+	return score
+}
+
+// snippet cuts a window of words around the first occurrence of any of the terms
+func snippet(content string, terms []string) string {
+	tokens := tokenize(content)
+	if len(tokens) == 0 {
+		return ""
+	}
+	wanted := map[string]bool{}
+	for _, term := range terms {
+		wanted[term] = true
+	}
+	first := 0
+	for i, t := range tokens {
+		if wanted[t.term] {
+			first = i
+			break
+		}
+	}
+	from, to := first-snippetRadius, first+snippetRadius
+	if from < 0 {
+		from = 0
+	}
+	if to >= len(tokens) {
+		to = len(tokens) - 1
+	}
+	text := collapseSpace(content[tokens[from].start:tokens[to].end])
+	if from > 0 {
+		text = "…" + text
+	}
+	if to < len(tokens)-1 {
+		text += "…"
+	}
+	return text
+}
+
+// indexData is the serialized form of an Index
+type indexData struct {
+	Docs        map[int]*indexedDocument
+	Postings    map[string]map[int][]int
+	TotalLength int
+}
+
+// WriteTo saves the index to w so it can be loaded with ReadIndex later instead of reindexing everything
+func (ix *Index) WriteTo(w io.Writer) (int64, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	counter := &countingWriter{w: w}
+	err := gob.NewEncoder(counter).Encode(indexData{
+		Docs:        ix.docs,
+		Postings:    ix.postings,
+		TotalLength: ix.totalLength,
+	})
+	return counter.n, err
+}
+
+// ReadIndex loads an index saved by WriteTo
+func ReadIndex(r io.Reader) (*Index, error) {
+	var data indexData
+	if err := gob.NewDecoder(r).Decode(&data); err != nil {
+		return nil, err
+	}
+	ix := NewIndex()
+	if data.Docs != nil {
+		ix.docs = data.Docs
+	}
+	if data.Postings != nil {
+		ix.postings = data.Postings
+	}
+	ix.totalLength = data.TotalLength
+	return ix, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+func testIndex() *Index {
+	ix := NewIndex()
+	ix.Add(Document{
+		Bookmark: instapaper.Bookmark{ID: 1, Title: "On Call Shouldn't Suck", URL: "https://charity.wtf/on-call", Starred: "1"},
+		Folders:  []string{instapaper.FolderIDUnread, instapaper.FolderIDStarred},
+		Text:     "<html><head><title>ignored</title></head><body><p>Managers should care about on call health.</p><script>var managers;</script></body></html>",
+	})
+	ix.Add(Document{
+		Bookmark: instapaper.Bookmark{ID: 2, Title: "Care and feeding of managers", Description: "A guide"},
+		Folders:  []string{instapaper.FolderIDArchive},
+		Highlights: []instapaper.Highlight{
+			{Text: "call your manager", Note: "good advice"},
+		},
+	})
+	ix.Add(Document{
+		Bookmark: instapaper.Bookmark{ID: 3, Title: "Gardening", Description: "Nothing about work"},
+		Folders:  []string{instapaper.FolderIDArchive},
+	})
+	return ix
+}
+
+func resultIDs(results []SearchResult) []int {
+	var ids []int
+	for _, result := range results {
+		ids = append(ids, result.Bookmark.ID)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	ix := testIndex()
+	cases := map[string][]int{
+		"managers":                    {2, 1},
+		"MANAGERS health":             {1},
+		`"on call"`:                   {1},
+		`"call on"`:                   nil,
+		"advice":                      {2},
+		"managers folder:archive":     {2},
+		"managers is:starred":         {1},
+		"is:starred":                  {1},
+		"ignored":                     nil,
+		"var":                         nil,
+		"charity":                     {1},
+		`"suck https"`:                nil, // phrases don't span fields
+		"nonexistent":                 nil,
+		`"feeding of managers" guide`: {2},
+	}
+	for q, expected := range cases {
+		if ids := resultIDs(ix.Search(q)); !reflect.DeepEqual(ids, expected) {
+			t.Errorf("%v: expected %v, got %v", q, expected, ids)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	ix := NewIndex()
+	words := make([]string, 100)
+	for i := range words {
+		words[i] = "filler"
+	}
+	words[50] = "needle"
+	ix.Add(Document{
+		Bookmark: instapaper.Bookmark{ID: 1, Title: "Haystack"},
+		Text:     "<p>" + strings.Join(words, " ") + "</p>",
+	})
+	results := ix.Search("needle")
+	if len(results) != 1 {
+		t.Fatalf("expected a single result, got %v", results)
+	}
+	snippet := results[0].Snippet
+	if !strings.Contains(snippet, "needle") || !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") {
+		t.Errorf("expected an excerpt around the match, got %v", snippet)
+	}
+}
+
+func TestRemoveAndReplace(t *testing.T) {
+	ix := testIndex()
+	ix.Remove(1)
+	if ids := resultIDs(ix.Search("managers")); !reflect.DeepEqual(ids, []int{2}) {
+		t.Errorf("expected only bookmark 2, got %v", ids)
+	}
+	ix.Add(Document{Bookmark: instapaper.Bookmark{ID: 2, Title: "Renamed"}})
+	if ids := resultIDs(ix.Search("managers")); ids != nil {
+		t.Errorf("expected the old version to be gone, got %v", ids)
+	}
+	if ix.Len() != 2 {
+		t.Errorf("expected 2 documents, got %d", ix.Len())
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	ix := testIndex()
+	var buf bytes.Buffer
+	if _, err := ix.WriteTo(&buf); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	loaded, err := ReadIndex(&buf)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if !reflect.DeepEqual(ix.Search(`"on call" managers`), loaded.Search(`"on call" managers`)) {
+		t.Errorf("expected the loaded index to give the same results")
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	text := htmlToText(`<p>foo<b>bar</b> un<em>believ</em>able</p><p>next<br/>line</p><script>var x</script>`)
+	if text != "foobar unbelievable\n\nnext\nline" {
+		t.Errorf("expected inline tags to keep words whole, got %q", text)
+	}
+
+	ix := NewIndex()
+	ix.Add(Document{Bookmark: instapaper.Bookmark{ID: 1}, Text: `<p>a <a href="/x">gopher</a>phile wrote <em>this</em> here</p>`})
+	if results := ix.Search("gopherphile"); len(results) != 1 {
+		t.Errorf("expected a word split by a link to be found, got %+v", results)
+	}
+	if results := ix.Search(`"wrote this here"`); len(results) != 1 {
+		t.Errorf("expected a phrase across inline tags to be found, got %+v", results)
+	}
+}
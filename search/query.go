@@ -0,0 +1,82 @@
+package search
+
+import "strings"
+
+// query is the parsed form of a search string
+type query struct {
+	terms       []string   // every term must be present
+	phrases     [][]string // every phrase must be present as consecutive terms
+	folder      string
+	starredOnly bool
+}
+
+// parseQuery understands bare terms, "quoted phrases" and the folder:<folder id> and is:starred filters
+func parseQuery(s string) query {
+	var q query
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if s == "" {
+			break
+		}
+		if s[0] == '"' {
+			end := strings.IndexByte(s[1:], '"')
+			var phrase string
+			if end < 0 {
+				phrase, s = s[1:], ""
+			} else {
+				phrase, s = s[1:end+1], s[end+2:]
+			}
+			var terms []string
+			for _, t := range tokenize(phrase) {
+				terms = append(terms, t.term)
+			}
+			switch len(terms) {
+			case 0:
+			case 1:
+				q.terms = append(q.terms, terms[0])
+			default:
+				q.phrases = append(q.phrases, terms)
+			}
+			continue
+		}
+		word := s
+		if end := strings.IndexAny(s, " \t\r\n"); end >= 0 {
+			word, s = s[:end], s[end:]
+		} else {
+			s = ""
+		}
+		lower := strings.ToLower(word)
+		switch {
+		case strings.HasPrefix(lower, "folder:") && len(word) > len("folder:"):
+			q.folder = word[len("folder:"):]
+		case lower == "is:starred":
+			q.starredOnly = true
+		default:
+			for _, t := range tokenize(word) {
+				q.terms = append(q.terms, t.term)
+			}
+		}
+	}
+	return q
+}
+
+// allTerms returns the distinct terms of the query, including the ones in phrases
+func (q query) allTerms() []string {
+	seen := map[string]bool{}
+	var terms []string
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	for _, term := range q.terms {
+		add(term)
+	}
+	for _, phrase := range q.phrases {
+		for _, term := range phrase {
+			add(term)
+		}
+	}
+	return terms
+}
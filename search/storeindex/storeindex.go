@@ -0,0 +1,43 @@
+// Package storeindex builds a search index from a local mirror. It's kept apart from the search package, which is pure
+// Go, because the store needs cgo for SQLite.
+package storeindex
+
+import (
+	"github.com/ochronus/instapaper-go-client/search"
+	"github.com/ochronus/instapaper-go-client/store"
+)
+
+// Build indexes every bookmark of a local mirror along with its stored text and highlights
+func Build(s *store.Store) (*search.Index, error) {
+	ix := search.NewIndex()
+	folders, err := s.Folders()
+	if err != nil {
+		return nil, err
+	}
+	indexed := map[int]bool{}
+	for _, folder := range folders {
+		bookmarks, err := s.Bookmarks(folder.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		for _, bookmark := range bookmarks {
+			if indexed[bookmark.ID] {
+				continue
+			}
+			indexed[bookmark.ID] = true
+			doc := search.Document{Bookmark: bookmark}
+			if doc.Folders, err = s.FoldersOf(bookmark.ID); err != nil {
+				return nil, err
+			}
+			if doc.Highlights, err = s.Highlights(bookmark.ID); err != nil {
+				return nil, err
+			}
+			doc.Text, err = s.Text(bookmark.ID)
+			if err != nil && err != store.ErrNotFound {
+				return nil, err
+			}
+			ix.Add(doc)
+		}
+	}
+	return ix, nil
+}
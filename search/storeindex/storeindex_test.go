@@ -0,0 +1,47 @@
+package storeindex
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+	"github.com/ochronus/instapaper-go-client/store"
+)
+
+func TestBuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "instapaper-storeindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s, err := store.Open(filepath.Join(dir, "mirror.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.UpsertFolder(instapaper.Folder{ID: "100", Title: "Reading"}); err != nil {
+		t.Fatal(err)
+	}
+	bookmark := instapaper.Bookmark{ID: 1, Title: "Gophers", URL: "https://example.com/gophers"}
+	if err := s.UpsertBookmark(bookmark, "100"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveText(1, "<p>burrowing rodents</p>"); err != nil {
+		t.Fatal(err)
+	}
+
+	ix, err := Build(s)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if ix.Len() != 1 {
+		t.Errorf("expected 1 indexed bookmark, got %d", ix.Len())
+	}
+	results := ix.Search("rodents folder:100")
+	if len(results) != 1 || results[0].Bookmark.ID != 1 {
+		t.Errorf("expected the bookmark to be found through its text and folder, got %+v", results)
+	}
+}
@@ -0,0 +1,88 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// token is a term along with where it was found in the text
+type token struct {
+	term       string
+	start, end int // byte offsets
+}
+
+// tokenize splits text into lowercase terms made of letters and digits
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWordRune && start < 0 {
+			start = i
+		}
+		if !isWordRune && start >= 0 {
+			tokens = append(tokens, token{term: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// skippedElements don't hold readable text
+var skippedElements = map[string]bool{
+	"script": true,
+	"style":  true,
+	"head":   true,
+}
+
+// blockElements break the text, the inline ones - b, a, em... - may well be in the middle of a word
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "hr": true, "li": true, "ul": true, "ol": true, "dt": true, "dd": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"tr": true, "td": true, "th": true, "table": true, "blockquote": true, "pre": true,
+	"section": true, "article": true, "header": true, "footer": true,
+}
+
+// htmlToText returns the readable text of an HTML document, block elements separated by newlines
+func htmlToText(doc string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(doc))
+	skipping := 0
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return strings.TrimSpace(b.String())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			if tt == html.StartTagToken && skippedElements[string(name)] {
+				skipping++
+			}
+			if blockElements[string(name)] {
+				b.WriteByte('\n')
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if skippedElements[string(name)] && skipping > 0 {
+				skipping--
+			}
+			if blockElements[string(name)] {
+				b.WriteByte('\n')
+			}
+		case html.TextToken:
+			if skipping == 0 {
+				b.Write(z.Text())
+			}
+		}
+	}
+}
+
+// collapseSpace turns every run of whitespace into a single space
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,81 @@
+package store
+
+import "fmt"
+
+// migrations are applied in order, the index of the last applied one plus one is kept in PRAGMA user_version.
+// Never change a migration that's already released - append a new one instead
+var migrations = []string{
+	`CREATE TABLE folders (
+		id             TEXT PRIMARY KEY,
+		title          TEXT NOT NULL,
+		slug           TEXT NOT NULL,
+		display_title  TEXT NOT NULL,
+		sync_to_mobile INTEGER NOT NULL,
+		position       TEXT NOT NULL,
+		built_in       INTEGER NOT NULL,
+		refreshed      INTEGER NOT NULL
+	);
+	CREATE TABLE bookmarks (
+		id                 INTEGER PRIMARY KEY,
+		hash               TEXT NOT NULL,
+		title              TEXT NOT NULL,
+		url                TEXT NOT NULL,
+		description        TEXT NOT NULL,
+		private_source     TEXT NOT NULL,
+		time               REAL NOT NULL,
+		progress           REAL NOT NULL,
+		progress_timestamp INTEGER NOT NULL,
+		starred            TEXT NOT NULL,
+		refreshed          INTEGER NOT NULL
+	);
+	CREATE TABLE bookmark_folders (
+		bookmark_id INTEGER NOT NULL REFERENCES bookmarks(id) ON DELETE CASCADE,
+		folder_id   TEXT NOT NULL REFERENCES folders(id) ON DELETE CASCADE,
+		PRIMARY KEY (bookmark_id, folder_id)
+	);
+	CREATE INDEX bookmark_folders_folder ON bookmark_folders(folder_id);
+	CREATE TABLE highlights (
+		id          INTEGER PRIMARY KEY,
+		bookmark_id INTEGER NOT NULL REFERENCES bookmarks(id) ON DELETE CASCADE,
+		text        TEXT NOT NULL,
+		note        TEXT NOT NULL,
+		time        TEXT NOT NULL,
+		position    INTEGER NOT NULL,
+		refreshed   INTEGER NOT NULL
+	);
+	CREATE INDEX highlights_bookmark ON highlights(bookmark_id);
+	CREATE TABLE texts (
+		bookmark_id INTEGER PRIMARY KEY REFERENCES bookmarks(id) ON DELETE CASCADE,
+		html        TEXT NOT NULL,
+		fetched_at  INTEGER NOT NULL
+	);`,
+}
+
+func (s *Store) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("store: database schema version %d is newer than the supported %d", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("store: migration %d failed: %v", i+1, err)
+		}
+		// PRAGMA doesn't take bound parameters
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
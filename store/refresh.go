@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// RefreshOptions tweak what Refresh does
+type RefreshOptions struct {
+	// FetchText makes Refresh download the text of every bookmark that doesn't have it stored yet
+	FetchText bool
+}
+
+// Refresh takes a snapshot of the account (see AccountService.Snapshot) and upserts everything into the store in a
+// single transaction. Folders, bookmarks and highlights missing from the snapshot are removed along with their texts.
+func (s *Store) Refresh(ctx context.Context, client *instapaper.Client, opts RefreshOptions) error {
+	account, err := client.Account.SnapshotContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.apply(ctx, account); err != nil {
+		return err
+	}
+	if opts.FetchText {
+		return s.fetchMissingTexts(ctx, client)
+	}
+	return nil
+}
+
+func (s *Store) apply(ctx context.Context, account *instapaper.Account) error {
+	generation := time.Now().UnixNano()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, folder := range account.Folders {
+		if err := upsertFolder(tx, folder, generation); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM folders WHERE refreshed != ?`, generation); err != nil {
+		return err
+	}
+
+	stored := map[int]bool{}
+	for _, bookmark := range account.Bookmarks {
+		if err := upsertBookmark(tx, bookmark.Bookmark, generation); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM bookmark_folders WHERE bookmark_id = ?`, bookmark.ID); err != nil {
+			return err
+		}
+		for _, folderID := range bookmark.Folders {
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO bookmark_folders (bookmark_id, folder_id) VALUES (?, ?)`, bookmark.ID, folderID); err != nil {
+				return err
+			}
+		}
+		stored[bookmark.ID] = true
+	}
+	if _, err := tx.Exec(`DELETE FROM bookmarks WHERE refreshed != ?`, generation); err != nil {
+		return err
+	}
+
+	for _, highlight := range account.Highlights {
+		if !stored[highlight.BookmarkID] {
+			continue
+		}
+		if err := upsertHighlight(tx, highlight, generation); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM highlights WHERE refreshed != ?`, generation); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) fetchMissingTexts(ctx context.Context, client *instapaper.Client) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT b.id FROM bookmarks b LEFT JOIN texts t ON t.bookmark_id = b.id
+		WHERE t.bookmark_id IS NULL ORDER BY b.id`)
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		html, err := client.Bookmarks.GetTextContext(ctx, id)
+		if apiErr, ok := err.(*instapaper.APIError); ok && apiErr.ErrorCode == instapaper.ErrTextGen {
+			// Instapaper can't make a text version of some pages, an empty text keeps them from being asked for again
+			html, err = "", nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.SaveText(id, html); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+// Package store mirrors an Instapaper account - folders, bookmarks, highlights and article texts - into a local SQLite
+// database, so reading lists can be queried without hitting the API. Refresh brings the mirror up to date.
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	// registers the sqlite3 database/sql driver
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// ErrNotFound is returned when the requested record isn't in the store
+var ErrNotFound = errors.New("store: not found")
+
+// Store is a local mirror of an Instapaper account backed by SQLite. It's safe for concurrent use
+type Store struct {
+	db *sql.DB
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Open opens - creating it if needed - the database at path and migrates its schema to the latest version
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=1&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer anyway, one connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// UpsertFolder inserts the folder or updates it if it's already stored
+func (s *Store) UpsertFolder(folder instapaper.Folder) error {
+	return upsertFolder(s.db, folder, time.Now().UnixNano())
+}
+
+// UpsertBookmark inserts the bookmark or updates it if it's already stored. The bookmark is added to the given folders,
+// which have to be stored already
+func (s *Store) UpsertBookmark(bookmark instapaper.Bookmark, folderIDs ...string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := upsertBookmark(tx, bookmark, time.Now().UnixNano()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, folderID := range folderIDs {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO bookmark_folders (bookmark_id, folder_id) VALUES (?, ?)`, bookmark.ID, folderID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UpsertHighlight inserts the highlight or updates it if it's already stored. Its bookmark has to be stored already
+func (s *Store) UpsertHighlight(highlight instapaper.Highlight) error {
+	return upsertHighlight(s.db, highlight, time.Now().UnixNano())
+}
+
+// SaveText stores the processed text-view HTML of the bookmark, see BookmarkService.GetText
+func (s *Store) SaveText(bookmarkID int, html string) error {
+	_, err := s.db.Exec(`INSERT INTO texts (bookmark_id, html, fetched_at) VALUES (?, ?, ?)
+		ON CONFLICT (bookmark_id) DO UPDATE SET html = excluded.html, fetched_at = excluded.fetched_at`,
+		bookmarkID, html, time.Now().Unix())
+	return err
+}
+
+func upsertFolder(db execer, folder instapaper.Folder, refreshed int64) error {
+	_, err := db.Exec(`INSERT INTO folders (id, title, slug, display_title, sync_to_mobile, position, built_in, refreshed)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET title = excluded.title, slug = excluded.slug, display_title = excluded.display_title,
+			sync_to_mobile = excluded.sync_to_mobile, position = excluded.position, built_in = excluded.built_in,
+			refreshed = excluded.refreshed`,
+		folder.ID.String(), folder.Title, folder.Slug, folder.DisplayTitle, folder.SyncToMobile, folder.Position.String(),
+		folder.BuiltIn, refreshed)
+	return err
+}
+
+func upsertBookmark(db execer, bookmark instapaper.Bookmark, refreshed int64) error {
+	_, err := db.Exec(`INSERT INTO bookmarks (id, hash, title, url, description, private_source, time, progress,
+			progress_timestamp, starred, refreshed)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET hash = excluded.hash, title = excluded.title, url = excluded.url,
+			description = excluded.description, private_source = excluded.private_source, time = excluded.time,
+			progress = excluded.progress, progress_timestamp = excluded.progress_timestamp, starred = excluded.starred,
+			refreshed = excluded.refreshed`,
+		bookmark.ID, bookmark.Hash, bookmark.Title, bookmark.URL, bookmark.Description, bookmark.PrivateSource,
+		bookmark.Time, bookmark.Progress, bookmark.ProgressTimestamp, bookmark.Starred, refreshed)
+	return err
+}
+
+func upsertHighlight(db execer, highlight instapaper.Highlight, refreshed int64) error {
+	_, err := db.Exec(`INSERT INTO highlights (id, bookmark_id, text, note, time, position, refreshed)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET bookmark_id = excluded.bookmark_id, text = excluded.text, note = excluded.note,
+			time = excluded.time, position = excluded.position, refreshed = excluded.refreshed`,
+		highlight.ID, highlight.BookmarkID, highlight.Text, highlight.Note, highlight.Time.String(), highlight.Position, refreshed)
+	return err
+}
+
+// Folders returns every stored folder, built-in ones first, custom ones by position
+func (s *Store) Folders() ([]instapaper.Folder, error) {
+	rows, err := s.db.Query(`SELECT id, title, slug, display_title, sync_to_mobile, position, built_in FROM folders
+		ORDER BY built_in DESC, CAST(position AS REAL), title`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var folders []instapaper.Folder
+	for rows.Next() {
+		var folder instapaper.Folder
+		var id, position string
+		if err := rows.Scan(&id, &folder.Title, &folder.Slug, &folder.DisplayTitle, &folder.SyncToMobile, &position, &folder.BuiltIn); err != nil {
+			return nil, err
+		}
+		folder.ID = json.Number(id)
+		folder.Position = json.Number(position)
+		folders = append(folders, folder)
+	}
+	return folders, rows.Err()
+}
+
+const bookmarkColumns = `b.id, b.hash, b.title, b.url, b.description, b.private_source, b.time, b.progress, b.progress_timestamp, b.starred`
+
+func scanBookmark(scanner interface{ Scan(...interface{}) error }) (instapaper.Bookmark, error) {
+	var b instapaper.Bookmark
+	err := scanner.Scan(&b.ID, &b.Hash, &b.Title, &b.URL, &b.Description, &b.PrivateSource, &b.Time, &b.Progress,
+		&b.ProgressTimestamp, &b.Starred)
+	return b, err
+}
+
+// Bookmarks returns the bookmarks stored in the folder, newest first
+func (s *Store) Bookmarks(folderID string) ([]instapaper.Bookmark, error) {
+	rows, err := s.db.Query(`SELECT `+bookmarkColumns+` FROM bookmarks b
+		JOIN bookmark_folders bf ON bf.bookmark_id = b.id
+		WHERE bf.folder_id = ? ORDER BY b.time DESC, b.id DESC`, folderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var bookmarks []instapaper.Bookmark
+	for rows.Next() {
+		bookmark, err := scanBookmark(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	return bookmarks, rows.Err()
+}
+
+// Bookmark returns a single bookmark or ErrNotFound
+func (s *Store) Bookmark(id int) (*instapaper.Bookmark, error) {
+	bookmark, err := scanBookmark(s.db.QueryRow(`SELECT `+bookmarkColumns+` FROM bookmarks b WHERE b.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bookmark, nil
+}
+
+// FoldersOf returns the IDs of the folders the bookmark is in
+func (s *Store) FoldersOf(bookmarkID int) ([]string, error) {
+	rows, err := s.db.Query(`SELECT folder_id FROM bookmark_folders WHERE bookmark_id = ? ORDER BY folder_id`, bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var folderIDs []string
+	for rows.Next() {
+		var folderID string
+		if err := rows.Scan(&folderID); err != nil {
+			return nil, err
+		}
+		folderIDs = append(folderIDs, folderID)
+	}
+	return folderIDs, rows.Err()
+}
+
+// Highlights returns the highlights of the bookmark in the order they appear in the text
+func (s *Store) Highlights(bookmarkID int) ([]instapaper.Highlight, error) {
+	rows, err := s.db.Query(`SELECT id, bookmark_id, text, note, time, position FROM highlights
+		WHERE bookmark_id = ? ORDER BY position, id`, bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var highlights []instapaper.Highlight
+	for rows.Next() {
+		var highlight instapaper.Highlight
+		var when string
+		if err := rows.Scan(&highlight.ID, &highlight.BookmarkID, &highlight.Text, &highlight.Note, &when, &highlight.Position); err != nil {
+			return nil, err
+		}
+		highlight.Time = json.Number(when)
+		highlights = append(highlights, highlight)
+	}
+	return highlights, rows.Err()
+}
+
+// Text returns the stored text-view HTML of the bookmark or ErrNotFound if it wasn't fetched yet, or Instapaper
+// couldn't make a text version of it
+func (s *Store) Text(bookmarkID int) (string, error) {
+	var html string
+	err := s.db.QueryRow(`SELECT html FROM texts WHERE bookmark_id = ?`, bookmarkID).Scan(&html)
+	if err == sql.ErrNoRows || (err == nil && html == "") {
+		return "", ErrNotFound
+	}
+	return html, err
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gomodule/oauth1/oauth"
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+func openTemp(t *testing.T) (*Store, string, func()) {
+	dir, err := ioutil.TempDir("", "instapaper-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "mirror.db")
+	s, err := Open(path)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("expected Open() to succeed, got %v", err)
+	}
+	return s, path, func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestOpenMigratesOnce(t *testing.T) {
+	s, path, cleanup := openTemp(t)
+	defer cleanup()
+	if err := s.UpsertFolder(instapaper.BuiltInFolders[0]); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	s.Close()
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("expected reopening to succeed, got %v", err)
+	}
+	defer reopened.Close()
+	folders, err := reopened.Folders()
+	if err != nil || len(folders) != 1 {
+		t.Errorf("expected the stored folder to survive reopening, got %v, %v", folders, err)
+	}
+}
+
+func TestUpsertAndQuery(t *testing.T) {
+	s, _, cleanup := openTemp(t)
+	defer cleanup()
+	folder := instapaper.Folder{ID: "100", Title: "Reading", Slug: "reading", Position: "1"}
+	if err := s.UpsertFolder(folder); err != nil {
+		t.Fatal(err)
+	}
+	bookmark := instapaper.Bookmark{ID: 1, Title: "first", URL: "https://example.com", Time: 1601750093, Starred: "0"}
+	if err := s.UpsertBookmark(bookmark, "100"); err != nil {
+		t.Fatal(err)
+	}
+	bookmark.Title = "renamed"
+	if err := s.UpsertBookmark(bookmark, "100"); err != nil {
+		t.Fatal(err)
+	}
+	highlight := instapaper.Highlight{ID: 10, BookmarkID: 1, Text: "quote", Time: "1601797631"}
+	if err := s.UpsertHighlight(highlight); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveText(1, "<p>hello</p>"); err != nil {
+		t.Fatal(err)
+	}
+
+	bookmarks, err := s.Bookmarks("100")
+	if err != nil || !reflect.DeepEqual(bookmarks, []instapaper.Bookmark{bookmark}) {
+		t.Errorf("expected %v, got %v, %v", bookmark, bookmarks, err)
+	}
+	highlights, err := s.Highlights(1)
+	if err != nil || !reflect.DeepEqual(highlights, []instapaper.Highlight{highlight}) {
+		t.Errorf("expected %v, got %v, %v", highlight, highlights, err)
+	}
+	if text, err := s.Text(1); err != nil || text != "<p>hello</p>" {
+		t.Errorf("expected the stored text, got %v, %v", text, err)
+	}
+	if _, err := s.Bookmark(2); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	bookmarkPages := map[string]string{
+		instapaper.FolderIDUnread: `{"bookmarks":[{"bookmark_id":1,"title":"one"},{"bookmark_id":2,"title":"two"}],
+			"highlights":[{"highlight_id":10,"bookmark_id":1,"text":"quote"}]}`,
+	}
+	textRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/account/verify_credentials":
+			fmt.Fprint(w, `[{"user_id":1,"username":"nope@nope.com"}]`)
+		case "/folders/list":
+			fmt.Fprint(w, `[]`)
+		case "/bookmarks/list":
+			page, ok := bookmarkPages[r.FormValue("folder_id")]
+			if !ok {
+				page = `{"bookmarks":[]}`
+			}
+			fmt.Fprint(w, page)
+		case "/bookmarks/get_text":
+			textRequests++
+			if r.FormValue("bookmark_id") == "2" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `[{"error_code":1550,"message":"Error generating text version of this URL"}]`)
+				return
+			}
+			fmt.Fprint(w, "<p>text of ", r.FormValue("bookmark_id"), "</p>")
+		default:
+			t.Errorf("unexpected call to %v", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client, _ := instapaper.NewClient("client_id", "client_secret", "username", "password", instapaper.WithBaseURL(server.URL))
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+
+	s, _, cleanup := openTemp(t)
+	defer cleanup()
+	// a leftover from an earlier refresh, it's gone from the account
+	s.UpsertFolder(instapaper.BuiltInFolders[0])
+	s.UpsertBookmark(instapaper.Bookmark{ID: 3}, instapaper.FolderIDUnread)
+
+	if err := s.Refresh(context.Background(), client, RefreshOptions{FetchText: true}); err != nil {
+		t.Fatalf("expected Refresh() to succeed, got %v", err)
+	}
+	bookmarks, err := s.Bookmarks(instapaper.FolderIDUnread)
+	if err != nil || len(bookmarks) != 2 {
+		t.Errorf("expected 2 bookmarks in the unread folder, got %v, %v", bookmarks, err)
+	}
+	if _, err := s.Bookmark(3); err != ErrNotFound {
+		t.Errorf("expected the stale bookmark to be removed, got %v", err)
+	}
+	folders, _ := s.Folders()
+	if len(folders) != len(instapaper.BuiltInFolders) {
+		t.Errorf("expected the built-in folders, got %v", folders)
+	}
+	if highlights, _ := s.Highlights(1); len(highlights) != 1 {
+		t.Errorf("expected the highlight to be stored, got %v", highlights)
+	}
+	if text, err := s.Text(1); err != nil || text != "<p>text of 1</p>" {
+		t.Errorf("expected the text to be fetched, got %v, %v", text, err)
+	}
+	if _, err := s.Text(2); err != ErrNotFound {
+		t.Errorf("expected no text for the bookmark Instapaper can't process, got %v", err)
+	}
+	if err := s.Refresh(context.Background(), client, RefreshOptions{FetchText: true}); err != nil {
+		t.Fatalf("expected Refresh() to succeed, got %v", err)
+	}
+	if textRequests != 2 {
+		t.Errorf("expected the texts to be requested once, got %d requests", textRequests)
+	}
+}
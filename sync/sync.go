@@ -0,0 +1,156 @@
+// Package sync keeps a local copy of the bookmarks of an Instapaper folder up to date cheaply.
+//
+// It relies on the "have" parameter of the bookmarks/list endpoint: the client tells the server which bookmarks it
+// already has - along with their hash and reading progress - and the server only sends back what's new or changed,
+// plus the IDs of bookmarks that are gone from the folder.
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+// State is the local knowledge about the bookmarks of a single folder
+type State struct {
+	Folder    string
+	Bookmarks map[int]instapaper.Bookmark
+}
+
+// Changeset describes how a sync changed the state
+type Changeset struct {
+	Added           []instapaper.Bookmark
+	Updated         []instapaper.Bookmark // title, URL, description, starred state or hash changed
+	ProgressChanged []instapaper.Bookmark // only the reading progress changed
+	Deleted         []int
+}
+
+// Empty tells whether nothing changed
+func (c *Changeset) Empty() bool {
+	return len(c.Added) == 0 && len(c.Updated) == 0 && len(c.ProgressChanged) == 0 && len(c.Deleted) == 0
+}
+
+func (c *Changeset) merge(other *Changeset) {
+	c.Added = append(c.Added, other.Added...)
+	c.Updated = append(c.Updated, other.Updated...)
+	c.ProgressChanged = append(c.ProgressChanged, other.ProgressChanged...)
+	c.Deleted = append(c.Deleted, other.Deleted...)
+}
+
+// NewState returns an empty state for the folder
+func NewState(folder string) *State {
+	return &State{
+		Folder:    folder,
+		Bookmarks: map[int]instapaper.Bookmark{},
+	}
+}
+
+// LoadState reads a state previously written by Save. A missing file results in an empty state for the folder
+func LoadState(path string, folder string) (*State, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewState(folder), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := NewState(folder)
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Bookmarks == nil {
+		state.Bookmarks = map[int]instapaper.Bookmark{}
+	}
+	return state, nil
+}
+
+// Save writes the state as JSON to path
+func (s *State) Save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// HaveParam builds the value of the "have" parameter from the known bookmarks:
+// a comma separated list of id:hash:progress:progress_timestamp entries
+func (s *State) HaveParam() string {
+	ids := make([]int, 0, len(s.Bookmarks))
+	for id := range s.Bookmarks {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	entries := make([]string, 0, len(ids))
+	for _, id := range ids {
+		bookmark := s.Bookmarks[id]
+		entry := strconv.Itoa(id)
+		if bookmark.Hash != "" {
+			entry += ":" + bookmark.Hash +
+				":" + strconv.FormatFloat(float64(bookmark.Progress), 'f', -1, 32) +
+				":" + strconv.FormatInt(bookmark.ProgressTimestamp, 10)
+		}
+		entries = append(entries, entry)
+	}
+	return strings.Join(entries, ",")
+}
+
+// Apply merges a bookmarks/list response - requested with HaveParam - into the state and returns what changed
+func (s *State) Apply(res *instapaper.BookmarkListResponse) *Changeset {
+	changes := &Changeset{}
+	for _, bookmark := range res.Bookmarks {
+		known, ok := s.Bookmarks[bookmark.ID]
+		s.Bookmarks[bookmark.ID] = bookmark
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, bookmark)
+		case contentChanged(known, bookmark):
+			changes.Updated = append(changes.Updated, bookmark)
+		case known.Progress != bookmark.Progress || known.ProgressTimestamp != bookmark.ProgressTimestamp:
+			changes.ProgressChanged = append(changes.ProgressChanged, bookmark)
+		case known.Hash != bookmark.Hash:
+			changes.Updated = append(changes.Updated, bookmark)
+		}
+	}
+	for _, id := range res.DeleteIDs {
+		if _, ok := s.Bookmarks[id]; ok {
+			delete(s.Bookmarks, id)
+			changes.Deleted = append(changes.Deleted, id)
+		}
+	}
+	return changes
+}
+
+func contentChanged(a, b instapaper.Bookmark) bool {
+	return a.Title != b.Title || a.URL != b.URL || a.Description != b.Description ||
+		a.Starred != b.Starred || a.PrivateSource != b.PrivateSource
+}
+
+// Sync brings the state up to date with the server, fetching as many pages as needed, and returns everything that changed.
+// On error the state reflects the pages applied so far and the returned changeset describes them.
+func Sync(ctx context.Context, svc instapaper.BookmarkAPI, state *State) (*Changeset, error) {
+	pageSize := instapaper.DefaultBookmarkListRequestParams.Limit
+	changes := &Changeset{}
+	for {
+		res, err := svc.ListContext(ctx, instapaper.BookmarkListRequestParams{
+			Limit:           pageSize,
+			CustomHaveParam: state.HaveParam(),
+			Folder:          state.Folder,
+		})
+		if err != nil {
+			return changes, err
+		}
+		page := state.Apply(res)
+		changes.merge(page)
+		// a short page is the last one, a page that changed nothing means the server keeps repeating itself
+		if len(res.Bookmarks) < pageSize || page.Empty() {
+			return changes, nil
+		}
+	}
+}
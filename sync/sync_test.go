@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gomodule/oauth1/oauth"
+	"github.com/ochronus/instapaper-go-client/instapaper"
+)
+
+func newService(t *testing.T, handler http.HandlerFunc) (*instapaper.BookmarkService, func()) {
+	server := httptest.NewServer(handler)
+	client, err := instapaper.NewClient("client_id", "client_secret", "username", "password", instapaper.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Credentials = &oauth.Credentials{Token: "token", Secret: "secret"}
+	return client.Bookmarks, server.Close
+}
+
+func TestHaveParam(t *testing.T) {
+	state := NewState(instapaper.FolderIDUnread)
+	state.Bookmarks[2] = instapaper.Bookmark{ID: 2}
+	state.Bookmarks[1] = instapaper.Bookmark{ID: 1, Hash: "abc", Progress: 0.5, ProgressTimestamp: 1288584076}
+	expected := "1:abc:0.5:1288584076,2"
+	if have := state.HaveParam(); have != expected {
+		t.Errorf("expected %v, got %v", expected, have)
+	}
+}
+
+func TestSync(t *testing.T) {
+	var haves []string
+	service, teardown := newService(t, func(w http.ResponseWriter, r *http.Request) {
+		haves = append(haves, r.FormValue("have"))
+		fmt.Fprint(w, `{
+			"bookmarks":[
+				{"bookmark_id":1,"hash":"h1b","title":"renamed","progress":0.1},
+				{"bookmark_id":2,"hash":"h2b","title":"two","progress":0.9,"progress_timestamp":20},
+				{"bookmark_id":4,"hash":"h4","title":"four"}
+			],
+			"delete_ids":[3]
+		}`)
+	})
+	defer teardown()
+
+	state := NewState(instapaper.FolderIDUnread)
+	state.Bookmarks[1] = instapaper.Bookmark{ID: 1, Hash: "h1", Title: "one", Progress: 0.1}
+	state.Bookmarks[2] = instapaper.Bookmark{ID: 2, Hash: "h2", Title: "two", Progress: 0.2, ProgressTimestamp: 10}
+	state.Bookmarks[3] = instapaper.Bookmark{ID: 3, Hash: "h3", Title: "three"}
+
+	changes, err := Sync(context.Background(), service, state)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	if haves[0] != "1:h1:0.1:0,2:h2:0.2:10,3:h3:0:0" {
+		t.Errorf("unexpected have parameter %v", haves[0])
+	}
+	if len(changes.Added) != 1 || changes.Added[0].ID != 4 {
+		t.Errorf("expected bookmark 4 to be added, got %v", changes.Added)
+	}
+	if len(changes.Updated) != 1 || changes.Updated[0].ID != 1 {
+		t.Errorf("expected bookmark 1 to be updated, got %v", changes.Updated)
+	}
+	if len(changes.ProgressChanged) != 1 || changes.ProgressChanged[0].ID != 2 {
+		t.Errorf("expected the progress of bookmark 2 to change, got %v", changes.ProgressChanged)
+	}
+	if !reflect.DeepEqual(changes.Deleted, []int{3}) {
+		t.Errorf("expected bookmark 3 to be deleted, got %v", changes.Deleted)
+	}
+	if _, ok := state.Bookmarks[3]; ok || len(state.Bookmarks) != 3 {
+		t.Errorf("expected the state to hold bookmarks 1, 2 and 4, got %v", state.Bookmarks)
+	}
+}
+
+func TestStateSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "instapaper-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	state, err := LoadState(path, instapaper.FolderIDArchive)
+	if err != nil || len(state.Bookmarks) != 0 || state.Folder != instapaper.FolderIDArchive {
+		t.Fatalf("expected an empty state, got %v, %v", state, err)
+	}
+	state.Bookmarks[1] = instapaper.Bookmark{ID: 1, Hash: "h1"}
+	if err := state.Save(path); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+	loaded, err := LoadState(path, instapaper.FolderIDArchive)
+	if err != nil || !reflect.DeepEqual(loaded, state) {
+		t.Errorf("expected %v, got %v, %v", state, loaded, err)
+	}
+}